@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// About is the version information the main server reports in /about
+type About struct {
+	DemistoVersion string `json:"demistoVersion"`
+	Version        string `json:"version"`
+}
+
+// ServerVersion returns the version of the server, or an empty string when it reports none
+func (a About) ServerVersion() string {
+	if len(a.DemistoVersion) > 0 {
+		return a.DemistoVersion
+	}
+	return a.Version
+}
+
+// GetAbout returns the version information of the main server
+func (c *Client) GetAbout(ctx context.Context) (*About, error) {
+	var record map[string]interface{}
+	_, err := c.DoJSON(ctx, http.MethodGet, "/about", nil, &record)
+	if err != nil {
+		return nil, err
+	}
+	var about About
+	if err := decode("about", record, &about); err != nil {
+		return nil, err
+	}
+	return &about, nil
+}
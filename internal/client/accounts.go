@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// Account is a tenant of a multi-tenant deployment. Name is the name used in paths of the API, e.g. acc_tenant1,
+// DisplayName the name it was created with.
+type Account struct {
+	Id                string   `json:"id"`
+	Name              string   `json:"name"`
+	DisplayName       string   `json:"displayName"`
+	HostGroupId       string   `json:"hostGroupId"`
+	Status            string   `json:"status"`
+	PropagationLabels []string `json:"propagationLabels"`
+	Roles             struct {
+		Roles []string `json:"roles"`
+	} `json:"roles"`
+}
+
+// Ready reports whether the account has finished being created. Accounts being created have no status yet.
+func (a Account) Ready() bool {
+	return a.Status != ""
+}
+
+// accountDetails is the entry of an account in the account details, which hold the roles of the account
+type accountDetails struct {
+	Name  string `json:"name"`
+	Roles []struct {
+		Name string `json:"name"`
+	} `json:"roles"`
+}
+
+// ListAccounts returns all accounts of the deployment
+func (c *Client) ListAccounts(ctx context.Context) ([]Account, error) {
+	records, _, err := c.api.DefaultApi.ListAccounts(ctx).Execute()
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]Account, 0, len(records))
+	for _, record := range records {
+		var account Account
+		if err := decode("account", record, &account); err != nil {
+			return nil, err
+		}
+		if account.Name == "" {
+			return nil, fmt.Errorf("unexpected account payload: account %q has no name", account.DisplayName)
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// FindAccount returns the account created with the given name, or nil if there is none
+func (c *Client) FindAccount(ctx context.Context, name string) (*Account, error) {
+	accounts, err := c.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range accounts {
+		if accounts[i].Name == accountPath(name) {
+			return &accounts[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ListAccountRoles returns the names of the roles of every account, by the name of the account in paths of the API
+func (c *Client) ListAccountRoles(ctx context.Context) (map[string][]string, error) {
+	records, _, err := c.api.DefaultApi.ListAccountsDetails(ctx).Execute()
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[string][]string, len(records))
+	for _, record := range records {
+		// the details hold other entries besides the accounts
+		if _, ok := record.(map[string]interface{}); !ok {
+			continue
+		}
+		var details accountDetails
+		if err := decode("account details", record, &details); err != nil {
+			return nil, err
+		}
+		for _, role := range details.Roles {
+			roles[details.Name] = append(roles[details.Name], role.Name)
+		}
+	}
+	return roles, nil
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// APIKey is an API key of the main account or of an account. The value of a key cannot be read back.
+type APIKey struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// DecodeAPIKeys decodes the response of the API keys list
+func DecodeAPIKeys(payload []byte) ([]APIKey, error) {
+	var records []map[string]interface{}
+	if err := json.Unmarshal(payload, &records); err != nil {
+		return nil, fmt.Errorf("unexpected API keys payload: %s", err)
+	}
+	keys := make([]APIKey, 0, len(records))
+	for _, record := range records {
+		var key APIKey
+		if err := decode("API key", record, &key); err != nil {
+			return nil, err
+		}
+		if key.Id == "" {
+			return nil, fmt.Errorf("unexpected API key payload: API key %q has no id", key.Name)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// apiKeysPath returns the path of the API keys of the main account, or of the given account
+func apiKeysPath(account string) string {
+	if account == "" {
+		return "/apikeys"
+	}
+	return "/" + accountPath(url.PathEscape(account)) + "/apikeys"
+}
+
+// ListAPIKeys returns the API keys of the main account, or of the given account
+func (c *Client) ListAPIKeys(ctx context.Context, account string) ([]APIKey, error) {
+	var records json.RawMessage
+	_, err := c.DoJSON(ctx, http.MethodGet, apiKeysPath(account), nil, &records)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return DecodeAPIKeys(records)
+}
+
+// FindAPIKey returns the API key with the given name in the main account, or in the given account, or nil if there
+// is none
+func (c *Client) FindAPIKey(ctx context.Context, account string, name string) (*APIKey, error) {
+	keys, err := c.ListAPIKeys(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].Name == name {
+			return &keys[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetAPIKey returns the API key with the given id in the main account, or in the given account, or nil if there is
+// none
+func (c *Client) GetAPIKey(ctx context.Context, account string, id string) (*APIKey, error) {
+	keys, err := c.ListAPIKeys(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].Id == id {
+			return &keys[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateAPIKey creates an API key with the given name and value in the main account, or in the given account
+func (c *Client) CreateAPIKey(ctx context.Context, account string, name string, value string) error {
+	_, err := c.DoJSON(ctx, http.MethodPost, apiKeysPath(account), map[string]interface{}{
+		"name":   name,
+		"apikey": value,
+	}, nil)
+	return err
+}
+
+// RevokeAPIKey revokes the API key with the given id. The response is returned as well, so a key that was already
+// revoked can be told apart from other errors by its status.
+func (c *Client) RevokeAPIKey(ctx context.Context, account string, id string) (*http.Response, error) {
+	return c.DoJSON(ctx, http.MethodDelete, apiKeysPath(account)+"/"+url.PathEscape(id), nil, nil)
+}
@@ -0,0 +1,104 @@
+// Package client is a typed layer over the XSOAR API client. The generated client returns most records of the
+// multi-tenant API as untyped maps; this package decodes them into structs and reports a payload that does not match
+// what the provider expects as an error instead of a panic.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Client wraps the API client of the provider
+type Client struct {
+	api *openapi.APIClient
+}
+
+// New returns a Client that sends its requests through api, with its configuration, headers and transport
+func New(api *openapi.APIClient) *Client {
+	return &Client{api: api}
+}
+
+// Send sends a request to the main server for endpoints, or fields, the API client does not cover. It uses the
+// client's configuration, so it has the same headers and transport as every other call. A response with an error
+// status is returned as an error, after its body has been read and closed.
+func (c *Client) Send(ctx context.Context, method string, path string, body interface{}) (*http.Response, error) {
+	cfg := c.api.GetConfig()
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(cfg.Servers[0].URL, "/")+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range cfg.DefaultHeader {
+		req.Header.Set(name, value)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return resp, fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, string(respBody))
+	}
+	return resp, nil
+}
+
+// DoJSON sends a request with Send and decodes the response body into out when out is not nil
+func (c *Client) DoJSON(ctx context.Context, method string, path string, body interface{}, out interface{}) (*http.Response, error) {
+	resp, err := c.Send(ctx, method, path, body)
+	if err != nil {
+		return resp, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, err
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp, fmt.Errorf("could not decode response of %s %s: %s", method, path, err)
+		}
+	}
+	return resp, nil
+}
+
+// decode converts a record returned by the API client into out. kind names the record in the error.
+func decode(kind string, record interface{}, out interface{}) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("unexpected %s payload: %s", kind, err)
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return fmt.Errorf("unexpected %s payload: %s", kind, err)
+	}
+	return nil
+}
+
+// accountPath returns the name of an account as used in paths of the API, e.g. acc_tenant1
+func accountPath(name string) string {
+	return "acc_" + name
+}
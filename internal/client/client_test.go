@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testClient returns a Client for a server answering every request with status 200 and body
+func testClient(t *testing.T, body string) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	cfg := openapi.NewConfiguration()
+	cfg.Servers[0].URL = server.URL
+	cfg.HTTPClient = server.Client()
+	return New(openapi.NewAPIClient(cfg))
+}
+
+func TestFindHAGroupByName(t *testing.T) {
+	c := testClient(t, `[{"id":"1","name":"group1","elasticsearchAddress":"http://es:9200","accountIds":["a1"]}]`)
+	group, err := c.FindHAGroupByName(context.Background(), "group1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group == nil || group.Id != "1" || group.ElasticsearchAddress != "http://es:9200" || len(group.AccountIds) != 1 {
+		t.Fatalf("unexpected group %+v", group)
+	}
+	group, err = c.FindHAGroupByName(context.Background(), "group2")
+	if err != nil || group != nil {
+		t.Fatalf("expected no group, got %+v, %v", group, err)
+	}
+}
+
+func TestFindHost(t *testing.T) {
+	c := testClient(t, `[{"id":"1","host":"host1","version":7,"serverVersion":"6.10.0","lastHeartBeat":"2022-08-01T10:00:00Z"},`+
+		`{"id":"2","host":"host2","version":3,"demistoVersion":"6.9.0"},{"id":"3","host":"host3","version":1}]`)
+	tests := map[string]struct {
+		name        string
+		wantVersion string
+	}{
+		"server version":  {"host1", "6.10.0"},
+		"demisto version": {"host2", "6.9.0"},
+		"no version":      {"host3", ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			host, err := c.FindHost(context.Background(), test.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if host == nil || host.Host != test.name {
+				t.Fatalf("unexpected host %+v", host)
+			}
+			if got := host.ReportedVersion(); got != test.wantVersion {
+				t.Fatalf("ReportedVersion() = %q, want %q", got, test.wantVersion)
+			}
+		})
+	}
+	host, err := c.FindHost(context.Background(), "host1")
+	if err != nil || host.Version != 7 || host.Heartbeat() != "2022-08-01T10:00:00Z" {
+		t.Fatalf("unexpected host %+v, %v", host, err)
+	}
+}
+
+func TestUnexpectedPayloads(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		call func(c *Client) error
+	}{
+		"HA group without id": {
+			body: `[{"name":"group1"}]`,
+			call: func(c *Client) error { _, err := c.ListHAGroups(context.Background()); return err },
+		},
+		"HA group with a numeric name": {
+			body: `[{"id":"1","name":1}]`,
+			call: func(c *Client) error { _, err := c.ListHAGroups(context.Background()); return err },
+		},
+		"host without name": {
+			body: `[{"id":"1","hostGroupId":"2"}]`,
+			call: func(c *Client) error { _, err := c.FindHost(context.Background(), "host1"); return err },
+		},
+		"account with string roles": {
+			body: `[{"name":"acc_tenant1","roles":"Administrator"}]`,
+			call: func(c *Client) error { _, err := c.FindAccount(context.Background(), "tenant1"); return err },
+		},
+		"engine without id": {
+			body: `{"engines":[{"name":"engine1"}]}`,
+			call: func(c *Client) error { _, err := c.FindEngine(context.Background(), "engine1"); return err },
+		},
+		"engine with a numeric status": {
+			body: `{"engines":[{"id":"1","name":"engine1","status":1}]}`,
+			call: func(c *Client) error { _, err := c.ListEngines(context.Background()); return err },
+		},
+		"engine group with string engine ids": {
+			body: `[{"id":"1","name":"group1","engineIds":"e1"}]`,
+			call: func(c *Client) error { _, err := c.FindEngineGroup(context.Background(), "group1"); return err },
+		},
+		"API key without id": {
+			body: `[{"name":"key1"}]`,
+			call: func(c *Client) error { _, err := c.FindAPIKey(context.Background(), "", "key1"); return err },
+		},
+		"API keys that are not a list": {
+			body: `{"name":"key1"}`,
+			call: func(c *Client) error { _, err := c.ListAPIKeys(context.Background(), ""); return err },
+		},
+		"about with a numeric version": {
+			body: `{"demistoVersion":6.8}`,
+			call: func(c *Client) error { _, err := c.GetAbout(context.Background()); return err },
+		},
+		"integration instance without id": {
+			body: `{"configurations":[],"instances":[{"name":"instance1"}]}`,
+			call: func(c *Client) error {
+				_, err := c.FindIntegrationInstance(context.Background(), "", "instance1")
+				return err
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.call(testClient(t, test.body))
+			if err == nil || !strings.HasPrefix(err.Error(), "unexpected ") {
+				t.Fatalf("expected an unexpected payload error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEngineConnectionStatus(t *testing.T) {
+	connected := true
+	disconnected := false
+	tests := map[string]struct {
+		engine Engine
+		want   string
+	}{
+		"status":       {Engine{Status: "Connected"}, "connected"},
+		"connected":    {Engine{Connected: &connected}, "connected"},
+		"disconnected": {Engine{Connected: &disconnected}, "disconnected"},
+		"neither":      {Engine{}, "unknown"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.engine.ConnectionStatus(); got != test.want {
+				t.Fatalf("ConnectionStatus() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Engine is a d1 engine registered with the main server. Some versions report the connection as a status, others as
+// a connected flag.
+type Engine struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Connected *bool  `json:"connected"`
+}
+
+// ConnectionStatus returns the connection status of the engine in lower case, e.g. connected or disconnected
+func (e Engine) ConnectionStatus() string {
+	if len(e.Status) > 0 {
+		return strings.ToLower(e.Status)
+	}
+	if e.Connected != nil {
+		if *e.Connected {
+			return "connected"
+		}
+		return "disconnected"
+	}
+	return "unknown"
+}
+
+// EngineGroup is a group of engines integration instances can be run on
+type EngineGroup struct {
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	EngineIds []string `json:"engineIds"`
+}
+
+func decodeEngine(record interface{}) (Engine, error) {
+	var engine Engine
+	if err := decode("engine", record, &engine); err != nil {
+		return engine, err
+	}
+	if engine.Id == "" {
+		return engine, fmt.Errorf("unexpected engine payload: engine %q has no id", engine.Name)
+	}
+	return engine, nil
+}
+
+func decodeEngineGroup(record interface{}) (EngineGroup, error) {
+	var group EngineGroup
+	if err := decode("engine group", record, &group); err != nil {
+		return group, err
+	}
+	if group.Id == "" {
+		return group, fmt.Errorf("unexpected engine group payload: engine group %q has no id", group.Name)
+	}
+	return group, nil
+}
+
+// ListEngines returns the engines registered with the main server
+func (c *Client) ListEngines(ctx context.Context) ([]Engine, error) {
+	var records struct {
+		Engines []map[string]interface{} `json:"engines"`
+	}
+	_, err := c.DoJSON(ctx, http.MethodPost, "/engines", map[string]interface{}{}, &records)
+	if err != nil {
+		return nil, err
+	}
+	engines := make([]Engine, 0, len(records.Engines))
+	for _, record := range records.Engines {
+		engine, err := decodeEngine(record)
+		if err != nil {
+			return nil, err
+		}
+		engines = append(engines, engine)
+	}
+	return engines, nil
+}
+
+// GetEngine returns the engine with the given id, or nil if there is none
+func (c *Client) GetEngine(ctx context.Context, id string) (*Engine, error) {
+	engines, err := c.ListEngines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range engines {
+		if engines[i].Id == id {
+			return &engines[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// FindEngine returns the engine with the given name, or nil if there is none
+func (c *Client) FindEngine(ctx context.Context, name string) (*Engine, error) {
+	engines, err := c.ListEngines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range engines {
+		if engines[i].Name == name {
+			return &engines[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// BuildEngine creates an engine on the main server, which builds its installer
+func (c *Client) BuildEngine(ctx context.Context, name string) (*Engine, error) {
+	var record map[string]interface{}
+	_, err := c.DoJSON(ctx, http.MethodPost, "/engines/build", map[string]interface{}{
+		"name": name,
+		"type": "shell",
+	}, &record)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := decodeEngine(record)
+	if err != nil {
+		return nil, err
+	}
+	return &engine, nil
+}
+
+// DeleteEngine removes the engine with the given id from the main server
+func (c *Client) DeleteEngine(ctx context.Context, id string) error {
+	_, err := c.DoJSON(ctx, http.MethodDelete, "/engines/"+url.PathEscape(id), nil, nil)
+	return err
+}
+
+// ListEngineGroups returns the engine groups of the main server
+func (c *Client) ListEngineGroups(ctx context.Context) ([]EngineGroup, error) {
+	var records []map[string]interface{}
+	_, err := c.DoJSON(ctx, http.MethodGet, "/engines/groups", nil, &records)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]EngineGroup, 0, len(records))
+	for _, record := range records {
+		group, err := decodeEngineGroup(record)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// FindEngineGroup returns the engine group with the given name, or nil if there is none
+func (c *Client) FindEngineGroup(ctx context.Context, name string) (*EngineGroup, error) {
+	groups, err := c.ListEngineGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].Name == name {
+			return &groups[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetEngineGroup returns the engine group with the given id. The response is returned as well, so a group that was
+// removed can be told apart from other errors by its status.
+func (c *Client) GetEngineGroup(ctx context.Context, id string) (*EngineGroup, *http.Response, error) {
+	var record map[string]interface{}
+	resp, err := c.DoJSON(ctx, http.MethodGet, "/engines/group/"+url.PathEscape(id), nil, &record)
+	if err != nil {
+		return nil, resp, err
+	}
+	group, err := decodeEngineGroup(record)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &group, resp, nil
+}
+
+// SaveEngineGroup creates an engine group, or updates the group with the given id when it is set
+func (c *Client) SaveEngineGroup(ctx context.Context, id string, name string, engineIds []string) (*EngineGroup, error) {
+	payload := map[string]interface{}{
+		"name":      name,
+		"engineIds": engineIds,
+	}
+	if len(id) > 0 {
+		payload["id"] = id
+	}
+	var record map[string]interface{}
+	_, err := c.DoJSON(ctx, http.MethodPost, "/engines/group", payload, &record)
+	if err != nil {
+		return nil, err
+	}
+	group, err := decodeEngineGroup(record)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// DeleteEngineGroup removes the engine group with the given id. The response is returned as well, so a group that
+// was already removed can be told apart from other errors by its status.
+func (c *Client) DeleteEngineGroup(ctx context.Context, id string) (*http.Response, error) {
+	return c.DoJSON(ctx, http.MethodDelete, "/engines/group/"+url.PathEscape(id), nil, nil)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// HAGroup is a group of hosts sharing an elasticsearch backend. A standalone host has a group of its own, named
+// after the host.
+type HAGroup struct {
+	Id                    string   `json:"id"`
+	Name                  string   `json:"name"`
+	ElasticsearchAddress  string   `json:"elasticsearchAddress"`
+	ElasticIndexPrefix    string   `json:"elasticIndexPrefix"`
+	ElasticsearchUsername string   `json:"elasticsearchUsername"`
+	ElasticsearchShards   *int64   `json:"elasticsearchShards"`
+	ElasticsearchReplicas *int64   `json:"elasticsearchReplicas"`
+	ElasticsearchCACert   string   `json:"elasticsearchCACert"`
+	ElasticsearchInsecure *bool    `json:"elasticsearchInsecure"`
+	AccountIds            []string `json:"accountIds"`
+	HostIds               []string `json:"hostIds"`
+}
+
+func decodeHAGroup(record interface{}) (HAGroup, error) {
+	var group HAGroup
+	if err := decode("HA group", record, &group); err != nil {
+		return group, err
+	}
+	if group.Id == "" {
+		return group, fmt.Errorf("unexpected HA group payload: HA group %q has no id", group.Name)
+	}
+	if group.Name == "" {
+		return group, fmt.Errorf("unexpected HA group payload: HA group %s has no name", group.Id)
+	}
+	return group, nil
+}
+
+// ListHAGroups returns all HA groups, including the groups of standalone hosts
+func (c *Client) ListHAGroups(ctx context.Context) ([]HAGroup, error) {
+	records, _, err := c.api.DefaultApi.ListHAGroups(ctx).Execute()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]HAGroup, 0, len(records))
+	for _, record := range records {
+		group, err := decodeHAGroup(record)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// FindHAGroupByName returns the HA group with the given name, or nil if there is none
+func (c *Client) FindHAGroupByName(ctx context.Context, name string) (*HAGroup, error) {
+	groups, err := c.ListHAGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].Name == name {
+			return &groups[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetHAGroup returns the HA group with the given id, including the elasticsearch settings the API client does not
+// cover
+func (c *Client) GetHAGroup(ctx context.Context, id string) (*HAGroup, error) {
+	var record map[string]interface{}
+	_, err := c.DoJSON(ctx, http.MethodGet, "/ha-group/"+url.PathEscape(id), nil, &record)
+	if err != nil {
+		return nil, err
+	}
+	group, err := decodeHAGroup(record)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// Host is a server running XSOAR that is registered with the main server
+type Host struct {
+	Id                   string `json:"id"`
+	Host                 string `json:"host"`
+	HostGroupId          string `json:"hostGroupId"`
+	ElasticsearchAddress string `json:"elasticsearchAddress"`
+	ElasticIndexPrefix   string `json:"elasticIndexPrefix"`
+	Status               string `json:"status"`
+	// Version is the revision of the host record, not the server version
+	Version        int64  `json:"version"`
+	ServerVersion  string `json:"serverVersion"`
+	DemistoVersion string `json:"demistoVersion"`
+	// LastHeartbeat is also filled from lastHeartBeat, which some versions send instead
+	LastHeartbeat string `json:"lastHeartbeat"`
+	LastUpdate    string `json:"lastUpdate"`
+}
+
+// ReportedVersion returns the server version the host reports, or an empty string when it does not report one
+func (h Host) ReportedVersion() string {
+	for _, version := range []string{h.ServerVersion, h.DemistoVersion} {
+		if len(version) > 0 {
+			return version
+		}
+	}
+	return ""
+}
+
+// Heartbeat returns the time the host last reported to the main server, or an empty string
+func (h Host) Heartbeat() string {
+	if len(h.LastHeartbeat) > 0 {
+		return h.LastHeartbeat
+	}
+	return h.LastUpdate
+}
+
+// ListHosts returns all hosts registered with the main server
+func (c *Client) ListHosts(ctx context.Context) ([]Host, error) {
+	records, _, err := c.api.DefaultApi.ListHosts(ctx).Execute()
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]Host, 0, len(records))
+	for _, record := range records {
+		var host Host
+		if err := decode("host", record, &host); err != nil {
+			return nil, err
+		}
+		if host.Host == "" {
+			return nil, fmt.Errorf("unexpected host payload: host %s has no name", host.Id)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// FindHost returns the host with the given name, or nil if there is none
+func (c *Client) FindHost(ctx context.Context, name string) (*Host, error) {
+	hosts, err := c.ListHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hosts {
+		if hosts[i].Host == name {
+			return &hosts[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+)
+
+// integrationsPageSize is the number of integrations and instances requested at once
+const integrationsPageSize = 500
+
+// Integration is an installed integration, the template instances are created from
+type Integration struct {
+	Name              string                   `json:"name"`
+	Category          string                   `json:"category"`
+	CanGetSamples     bool                     `json:"canGetSamples"`
+	IntegrationScript interface{}              `json:"integrationScript"`
+	Configuration     []map[string]interface{} `json:"configuration"`
+	// Record is the integration as returned by the server, which is sent back when an instance is created
+	Record map[string]interface{} `json:"-"`
+}
+
+// IntegrationInstance is a configured instance of an integration. The mapper ids are nil when the instance has none.
+type IntegrationInstance struct {
+	Id                string   `json:"id"`
+	Name              string   `json:"name"`
+	Brand             string   `json:"brand"`
+	IncomingMapperId  *string  `json:"incomingMapperId"`
+	MappingId         *string  `json:"mappingId"`
+	PropagationLabels []string `json:"propagationLabels"`
+	// Version is the version the instance is saved with, which an update has to send back
+	Version int64 `json:"version"`
+}
+
+// DecodeIntegrationInstance decodes an integration instance record, such as the one returned when an instance is
+// created or updated
+func DecodeIntegrationInstance(record map[string]interface{}) (*IntegrationInstance, error) {
+	var instance IntegrationInstance
+	if err := decode("integration instance", record, &instance); err != nil {
+		return nil, err
+	}
+	if instance.Id == "" {
+		return nil, fmt.Errorf("unexpected integration instance payload: instance %q has no id", instance.Name)
+	}
+	return &instance, nil
+}
+
+// integrations is the response of the integrations search, holding the integrations and their instances
+type integrations struct {
+	Configurations []map[string]interface{} `json:"configurations"`
+	Instances      []map[string]interface{} `json:"instances"`
+}
+
+// listIntegrations searches the integrations of the main account, or of the given account
+func (c *Client) listIntegrations(ctx context.Context, account string) (*integrations, error) {
+	var records map[string]interface{}
+	var err error
+	if account == "" {
+		size := openapi.NewInlineObject2()
+		size.SetSize(integrationsPageSize)
+		records, _, err = c.api.DefaultApi.ListIntegrations(ctx).Size(*size).Execute()
+	} else {
+		size := openapi.NewInlineObject3()
+		size.SetSize(integrationsPageSize)
+		records, _, err = c.api.DefaultApi.ListIntegrationsAccount(ctx, accountPath(account)).Size(*size).Execute()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result integrations
+	if err := decode("integrations", records, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FindIntegrationByBrand returns the integration of the main account with the given brand, the name instances refer
+// to it by, or nil if there is none
+func (c *Client) FindIntegrationByBrand(ctx context.Context, brand string) (*Integration, error) {
+	result, err := c.listIntegrations(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range result.Configurations {
+		var integration Integration
+		if err := decode("integration", record, &integration); err != nil {
+			return nil, err
+		}
+		if integration.Name == brand {
+			integration.Record = record
+			return &integration, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindIntegrationInstance returns the instance with the given id or name in the main account, or in the given
+// account, or nil if there is none
+func (c *Client) FindIntegrationInstance(ctx context.Context, account string, identifier string) (*IntegrationInstance, error) {
+	result, err := c.listIntegrations(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range result.Instances {
+		instance, err := DecodeIntegrationInstance(record)
+		if err != nil {
+			return nil, err
+		}
+		if instance.Id == identifier || instance.Name == identifier {
+			return instance, nil
+		}
+	}
+	return nil, nil
+}
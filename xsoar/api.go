@@ -0,0 +1,45 @@
+package xsoar
+
+import (
+	"context"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"io"
+	"net/http"
+	"os"
+	"terraform-provider-xsoar/internal/client"
+)
+
+// sendRequest sends a request to the main server for endpoints, or fields, the API client does not cover. A response
+// with an error status is returned as an error, after its body has been read and closed.
+func sendRequest(ctx context.Context, api *openapi.APIClient, method string, path string, body interface{}) (*http.Response, error) {
+	return client.New(api).Send(ctx, method, path, body)
+}
+
+// doJSON sends a request with sendRequest and decodes the response body into out when out is not nil
+func doJSON(ctx context.Context, api *openapi.APIClient, method string, path string, body interface{}, out interface{}) (*http.Response, error) {
+	return client.New(api).DoJSON(ctx, method, path, body, out)
+}
+
+// downloadFile saves the response body of a GET request to a temporary file. The caller removes the file.
+func downloadFile(ctx context.Context, client *openapi.APIClient, path string) (*os.File, error) {
+	resp, err := sendRequest(ctx, client, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	f, err := os.CreateTemp("", "xsoar-download-")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,97 @@
+package xsoar
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignRequest(t *testing.T) {
+	// sha256("C2B8A7F4E1D6" + nonce + "1700000000000")
+	nonce := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789ab"
+	want := "c1301f6b6c685dc8abb4198b81591efd8181e0ea475c969a7aca01dabcf686a2"
+	if got := signRequest("C2B8A7F4E1D6", nonce, "1700000000000"); got != want {
+		t.Fatalf("signRequest = %s, want %s", got, want)
+	}
+}
+
+func TestApiAuthApply(t *testing.T) {
+	t.Run("standard", func(t *testing.T) {
+		header := http.Header{}
+		if err := (&apiAuth{apiKey: "C2B8A7F4E1D6", authType: authTypeStandard}).apply(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Get("Authorization") != "C2B8A7F4E1D6" {
+			t.Errorf("Authorization = %q, want the key", header.Get("Authorization"))
+		}
+		for _, name := range []string{"x-xdr-auth-id", "x-xdr-nonce", "x-xdr-timestamp"} {
+			if _, ok := header[http.CanonicalHeaderKey(name)]; ok {
+				t.Errorf("%s should not be set for a standard key without an id", name)
+			}
+		}
+	})
+
+	t.Run("standard with key id", func(t *testing.T) {
+		header := http.Header{}
+		if err := (&apiAuth{apiKey: "C2B8A7F4E1D6", apiKeyID: "12", authType: authTypeStandard}).apply(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Get("Authorization") != "C2B8A7F4E1D6" || header.Get("x-xdr-auth-id") != "12" {
+			t.Errorf("unexpected headers %v", header)
+		}
+	})
+
+	t.Run("advanced", func(t *testing.T) {
+		auth := &apiAuth{apiKey: "C2B8A7F4E1D6", apiKeyID: "12", authType: authTypeAdvanced}
+		header := http.Header{}
+		before := time.Now().UnixMilli()
+		if err := auth.apply(header); err != nil {
+			t.Fatal(err)
+		}
+		nonce := header.Get("x-xdr-nonce")
+		if !regexp.MustCompile(`^[a-zA-Z0-9]{64}$`).MatchString(nonce) {
+			t.Errorf("nonce %q should be 64 alphanumeric characters", nonce)
+		}
+		timestamp, err := strconv.ParseInt(header.Get("x-xdr-timestamp"), 10, 64)
+		if err != nil || timestamp < before || timestamp > time.Now().UnixMilli() {
+			t.Errorf("timestamp %q should be the current time in milliseconds", header.Get("x-xdr-timestamp"))
+		}
+		if want := signRequest(auth.apiKey, nonce, header.Get("x-xdr-timestamp")); header.Get("Authorization") != want {
+			t.Errorf("Authorization = %q, want %q", header.Get("Authorization"), want)
+		}
+		if header.Get("x-xdr-auth-id") != "12" {
+			t.Errorf("x-xdr-auth-id = %q, want 12", header.Get("x-xdr-auth-id"))
+		}
+
+		// every request is signed anew, the server rejects a nonce it has seen
+		again := http.Header{}
+		if err := auth.apply(again); err != nil {
+			t.Fatal(err)
+		}
+		if again.Get("x-xdr-nonce") == nonce || again.Get("Authorization") == header.Get("Authorization") {
+			t.Error("a second request should get a new nonce and signature")
+		}
+	})
+}
+
+func TestApiBaseUrl(t *testing.T) {
+	tests := []struct {
+		mainHost string
+		product  string
+		want     string
+	}{
+		{"https://xsoar.example.com", productXSOAR6, "https://xsoar.example.com"},
+		{"https://xsoar.example.com/", productXSOAR6, "https://xsoar.example.com"},
+		{"https://api-tenant.xdr.us.paloaltonetworks.com", productXSOAR8, "https://api-tenant.xdr.us.paloaltonetworks.com/xsoar"},
+		{"https://api-tenant.xdr.us.paloaltonetworks.com/", productXSIAM, "https://api-tenant.xdr.us.paloaltonetworks.com/xsoar"},
+		{"https://api-tenant.xdr.us.paloaltonetworks.com/xsoar", productXSOAR8, "https://api-tenant.xdr.us.paloaltonetworks.com/xsoar"},
+		{"https://api-tenant.xdr.us.paloaltonetworks.com/xsoar/", productXSIAM, "https://api-tenant.xdr.us.paloaltonetworks.com/xsoar"},
+	}
+	for _, test := range tests {
+		if got := apiBaseUrl(test.mainHost, test.product); got != test.want {
+			t.Errorf("apiBaseUrl(%q, %q) = %s, want %s", test.mainHost, test.product, got, test.want)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package xsoar
 
 import (
 	"context"
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -64,89 +63,33 @@ func (r dataSourceAccount) Read(ctx context.Context, req tfsdk.ReadDataSourceReq
 		return
 	}
 
-	// Get account from API and then update what is in config from what the API returns
-	accName := "acc_" + config.Name.Value
-
-	// Get account current value
-	account, _, err := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting account",
-			"Could not read account "+accName+": "+err.Error(),
-		)
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_account", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var propagationLabels []attr.Value
-	if account["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, prop := range account["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   prop.(string),
-			})
-		}
-	}
-
-	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
+	// Get account current value
+	account, err := r.p.api.FindAccount(ctx, config.Name.Value)
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error listing account details",
-			"Could not read account details"+err.Error(),
+			"Error getting account",
+			"Could not read account "+config.Name.Value+": "+err.Error(),
 		)
 		return
 	}
-	var roles []attr.Value
-	for _, detail := range details {
-		castDetail := detail.(map[string]interface{})
-		if account["name"].(string) == castDetail["name"].(string) {
-			roleObjects := castDetail["roles"].([]interface{})
-			for _, roleObject := range roleObjects {
-				roleName := roleObject.(map[string]interface{})["name"]
-				roles = append(roles, types.String{
-					Unknown: false,
-					Null:    false,
-					Value:   roleName.(string),
-				})
-			}
-		}
-	}
-	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
-	if err != nil {
+	if account == nil {
 		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
+			"Error getting account",
+			"Could not find account "+config.Name.Value,
 		)
 		return
 	}
-	var hostGroupName = ""
-	for _, group := range haGroups {
-		if group["id"].(string) == account["hostGroupId"].(string) {
-			hostGroupName = group["name"].(string)
-			break
-		}
-	}
 
 	// Map response body to resource schema attribute
-	config = Account{
-		Name:          types.String{Value: account["displayName"].(string)},
-		HostGroupName: types.String{Value: hostGroupName},
-		HostGroupId:   types.String{Value: account["hostGroupId"].(string)},
-		PropagationLabels: types.Set{
-			Unknown:  false,
-			Null:     false,
-			Elems:    propagationLabels,
-			ElemType: types.StringType,
-		},
-		AccountRoles: types.Set{
-			Unknown:  false,
-			Null:     false,
-			Elems:    propagationLabels,
-			ElemType: types.StringType,
-		},
-		Id: types.String{Value: account["id"].(string)},
+	config, diags = readAccountModel(ctx, r.p.api, account)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	// Set state
@@ -51,8 +51,13 @@ func (r dataSourceAccounts) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_accounts", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Get accounts current value
-	accounts, _, err := r.p.client.DefaultApi.ListAccounts(ctx).Execute()
+	accounts, err := r.p.api.ListAccounts(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting accounts",
@@ -60,19 +65,19 @@ func (r dataSourceAccounts) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 		)
 		return
 	}
-	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
+	accountRoles, err := r.p.api.ListAccountRoles(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error listing account details",
-			"Could not read account details"+err.Error(),
+			"Could not read account details: "+err.Error(),
 		)
 		return
 	}
-	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+	haGroups, err := r.p.api.ListHAGroups(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
+			"Could not read HA groups: "+err.Error(),
 		)
 		return
 	}
@@ -115,38 +120,18 @@ func (r dataSourceAccounts) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 			},
 		}
 		// assign the values from the response to the object
-		accountName, ok := account["displayName"].(string)
-		if ok {
-			accountObject.Attrs["name"] = types.String{Value: accountName}
-		}
-		accountHostGroupId, ok := account["hostGroupId"].(string)
-		if ok {
-			accountObject.Attrs["host_group_id"] = types.String{Value: accountHostGroupId}
-		}
+		accountObject.Attrs["name"] = types.String{Value: account.DisplayName}
+		accountObject.Attrs["host_group_id"] = types.String{Value: account.HostGroupId}
 		for _, group := range haGroups {
-			if group["id"].(string) == accountHostGroupId {
-				hostGroupName, ok := group["name"].(string)
-				if ok {
-					accountObject.Attrs["host_group_name"] = types.String{Value: hostGroupName}
-					break
-				}
+			if group.Id == account.HostGroupId {
+				accountObject.Attrs["host_group_name"] = types.String{Value: group.Name}
+				break
 			}
 		}
-		accountId, ok := account["id"].(string)
-		if ok {
-			accountObject.Attrs["id"] = types.String{Value: accountId}
-		}
-		var propagationLabels []attr.Value
-		if account["propagationLabels"] == nil {
-			propagationLabels = []attr.Value{}
-		} else {
-			for _, prop := range account["propagationLabels"].([]interface{}) {
-				propagationLabels = append(propagationLabels, types.String{
-					Unknown: false,
-					Null:    false,
-					Value:   prop.(string),
-				})
-			}
+		accountObject.Attrs["id"] = types.String{Value: account.Id}
+		propagationLabels := []attr.Value{}
+		for _, label := range account.PropagationLabels {
+			propagationLabels = append(propagationLabels, types.String{Value: label})
 		}
 		accountObject.Attrs["propagation_labels"] = types.Set{
 			Unknown:  false,
@@ -155,19 +140,8 @@ func (r dataSourceAccounts) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 			ElemType: types.StringType,
 		}
 		var roles []attr.Value
-		for _, detail := range details {
-			castDetail := detail.(map[string]interface{})
-			if account["name"].(string) == castDetail["name"].(string) {
-				roleObjects := castDetail["roles"].([]interface{})
-				for _, roleObject := range roleObjects {
-					roleName := roleObject.(map[string]interface{})["name"]
-					roles = append(roles, types.String{
-						Unknown: false,
-						Null:    false,
-						Value:   roleName.(string),
-					})
-				}
-			}
+		for _, role := range accountRoles[account.Name] {
+			roles = append(roles, types.String{Value: role})
 		}
 		accountObject.Attrs["account_roles"] = types.Set{
 			Unknown:  false,
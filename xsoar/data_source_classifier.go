@@ -8,9 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
 	"log"
-	"net/http"
 )
 
 type dataSourceClassifierType struct{}
@@ -75,19 +73,19 @@ func (r dataSourceClassifier) Read(ctx context.Context, req tfsdk.ReadDataSource
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_classifier", classifierMinVersion, false)...)
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(config.Account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Get resource from API
 	var classifier openapi.InstanceClassifier
-	var httpResponse *http.Response
 	var err error
 	if config.Account.Null || len(config.Account.Value) == 0 {
-		classifier, httpResponse, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(config.Name.Value).Execute()
+		classifier, _, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(config.Name.Value).Execute()
 	} else {
-		classifier, httpResponse, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+config.Account.Value).SetIdentifier(config.Name.Value).Execute()
-	}
-	if httpResponse != nil {
-		getBody, _ := httpResponse.Request.GetBody()
-		b, _ := io.ReadAll(getBody)
-		log.Println(string(b))
+		classifier, _, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+config.Account.Value).SetIdentifier(config.Name.Value).Execute()
 	}
 	if err != nil {
 		log.Println(err.Error())
@@ -0,0 +1,112 @@
+package xsoar
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"path"
+)
+
+type dataSourceEnginesType struct{}
+
+var engineObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"id":     types.StringType,
+		"name":   types.StringType,
+		"status": types.StringType,
+	},
+}
+
+func (r dataSourceEnginesType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"engines": {
+				Type:     types.SetType{ElemType: engineObjectType},
+				Computed: true,
+			},
+		},
+	}, nil
+}
+
+func (r dataSourceEnginesType) NewDataSource(_ context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	return dataSourceEngines{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+type dataSourceEngines struct {
+	p provider
+}
+
+func (r dataSourceEngines) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	// Declare struct that this function will set to this data source's config
+	var config Engines
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_engines", engineMinVersion, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get engines current value
+	engines, err := r.p.api.ListEngines(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error listing engines",
+			"Could not read engines: "+err.Error(),
+		)
+		return
+	}
+
+	var enginesEngines = types.Set{
+		Elems:    []attr.Value{},
+		ElemType: engineObjectType,
+	}
+	for _, engine := range engines {
+		name := engine.Name
+		// name is a shell pattern, e.g. "dmz-*"
+		if !config.Name.Null && len(config.Name.Value) > 0 {
+			matched, err := path.Match(config.Name.Value, name)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Invalid name pattern",
+					"Could not match engines against "+config.Name.Value+": "+err.Error(),
+				)
+				return
+			}
+			if !matched {
+				continue
+			}
+		}
+		enginesEngines.Elems = append(enginesEngines.Elems, types.Object{
+			Attrs: map[string]attr.Value{
+				"id":     types.String{Value: engine.Id},
+				"name":   types.String{Value: name},
+				"status": types.String{Value: engine.ConnectionStatus()},
+			},
+			AttrTypes: engineObjectType.AttrTypes,
+		})
+	}
+
+	result := Engines{
+		Name:    config.Name,
+		Engines: enginesEngines,
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -28,6 +28,26 @@ func (r dataSourceHAGroupType) GetSchema(_ context.Context) (tfsdk.Schema, diag.
 				Type:     types.StringType,
 				Computed: true,
 			},
+			"elasticsearch_username": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"elasticsearch_shards": {
+				Type:     types.Int64Type,
+				Computed: true,
+			},
+			"elasticsearch_replicas": {
+				Type:     types.Int64Type,
+				Computed: true,
+			},
+			"elasticsearch_ca_cert": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"elasticsearch_verify_tls": {
+				Type:     types.BoolType,
+				Computed: true,
+			},
 			"account_ids": {
 				Type:     types.SetType{ElemType: types.StringType},
 				Computed: true,
@@ -52,15 +72,20 @@ type dataSourceHAGroup struct {
 
 func (r dataSourceHAGroup) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
 	// Declare struct that this function will set to this data source's config
-	var config HAGroup
+	var config HAGroupDataSource
 	diags := req.Config.Get(ctx, &config)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_ha_group", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Get HA group from API and then update what is in config from what the API returns
-	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+	group, err := r.p.api.FindHAGroupByName(ctx, config.Name.Value)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error listing HA groups",
@@ -68,14 +93,23 @@ func (r dataSourceHAGroup) Read(ctx context.Context, req tfsdk.ReadDataSourceReq
 		)
 		return
 	}
-	var haGroupId string
-	for _, group := range haGroups {
-		if config.Name.Value == group["name"].(string) {
-			haGroupId = group["id"].(string)
-			break
-		}
+	if group == nil {
+		resp.Diagnostics.AddError(
+			"Error getting HA group",
+			"Could not find HA group "+config.Name.Value,
+		)
+		return
+	}
+	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, group.Id).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting HA group",
+			"Could not get HA group "+config.Name.Value+": "+err.Error(),
+		)
+		return
 	}
-	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, haGroupId).Execute()
+
+	record, err := r.p.api.GetHAGroup(ctx, haGroup.GetId())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting HA group",
@@ -83,13 +117,19 @@ func (r dataSourceHAGroup) Read(ctx context.Context, req tfsdk.ReadDataSourceReq
 		)
 		return
 	}
+	elastic := readHAGroupElastic(record)
 
 	// Map response body to resource schema attribute
-	config = HAGroup{
-		Name:               types.String{Value: haGroup.GetName()},
-		Id:                 types.String{Value: haGroup.GetId()},
-		ElasticsearchUrl:   types.String{Value: haGroup.GetElasticsearchAddress()},
-		ElasticIndexPrefix: types.String{Value: haGroup.GetElasticIndexPrefix()},
+	config = HAGroupDataSource{
+		Name:                   types.String{Value: haGroup.GetName()},
+		Id:                     types.String{Value: haGroup.GetId()},
+		ElasticsearchUrl:       types.String{Value: haGroup.GetElasticsearchAddress()},
+		ElasticIndexPrefix:     types.String{Value: haGroup.GetElasticIndexPrefix()},
+		ElasticsearchUsername:  elastic.Username,
+		ElasticsearchShards:    elastic.Shards,
+		ElasticsearchReplicas:  elastic.Replicas,
+		ElasticsearchCACert:    elastic.CACert,
+		ElasticsearchVerifyTLS: elastic.VerifyTLS,
 		AccountIds: types.Set{
 			Unknown:  false,
 			Null:     false,
@@ -26,12 +26,17 @@ func (r dataSourceHAGroupsType) GetSchema(_ context.Context) (tfsdk.Schema, diag
 				Type: types.SetType{
 					ElemType: types.ObjectType{
 						AttrTypes: map[string]attr.Type{
-							"name":                 types.StringType,
-							"id":                   types.StringType,
-							"elasticsearch_url":    types.StringType,
-							"elastic_index_prefix": types.StringType,
-							"account_ids":          types.SetType{ElemType: types.StringType},
-							"host_ids":             types.SetType{ElemType: types.StringType},
+							"name":                     types.StringType,
+							"id":                       types.StringType,
+							"elasticsearch_url":        types.StringType,
+							"elastic_index_prefix":     types.StringType,
+							"elasticsearch_username":   types.StringType,
+							"elasticsearch_shards":     types.Int64Type,
+							"elasticsearch_replicas":   types.Int64Type,
+							"elasticsearch_ca_cert":    types.StringType,
+							"elasticsearch_verify_tls": types.BoolType,
+							"account_ids":              types.SetType{ElemType: types.StringType},
+							"host_ids":                 types.SetType{ElemType: types.StringType},
 						},
 					},
 				},
@@ -60,8 +65,13 @@ func (r dataSourceHAGroups) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_ha_groups", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Get HA group from API and then update what is in config from what the API returns
-	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+	haGroups, err := r.p.api.ListHAGroups(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error listing HA groups",
@@ -76,27 +86,29 @@ func (r dataSourceHAGroups) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 		Elems:   nil,
 		ElemType: types.ObjectType{
 			AttrTypes: map[string]attr.Type{
-				"name":                 types.StringType,
-				"id":                   types.StringType,
-				"elasticsearch_url":    types.StringType,
-				"elastic_index_prefix": types.StringType,
-				"account_ids":          types.SetType{ElemType: types.StringType},
-				"host_ids":             types.SetType{ElemType: types.StringType},
+				"name":                     types.StringType,
+				"id":                       types.StringType,
+				"elasticsearch_url":        types.StringType,
+				"elastic_index_prefix":     types.StringType,
+				"elasticsearch_username":   types.StringType,
+				"elasticsearch_shards":     types.Int64Type,
+				"elasticsearch_replicas":   types.Int64Type,
+				"elasticsearch_ca_cert":    types.StringType,
+				"elasticsearch_verify_tls": types.BoolType,
+				"account_ids":              types.SetType{ElemType: types.StringType},
+				"host_ids":                 types.SetType{ElemType: types.StringType},
 			},
 		},
 	}
 	for _, group := range haGroups {
 		if !config.Name.Null {
-			if !glob.Glob(config.Name.Value, group["name"].(string)) {
+			if !glob.Glob(config.Name.Value, group.Name) {
 				continue
 			}
 		}
 		if !config.MaxAccounts.Null {
-			accountIds, ok := group["accountIds"].([]interface{})
-			if ok {
-				if int64(len(accountIds)) > config.MaxAccounts.Value {
-					continue
-				}
+			if int64(len(group.AccountIds)) > config.MaxAccounts.Value {
+				continue
 			}
 		}
 		haGroupsGroups.Null = false
@@ -105,10 +117,15 @@ func (r dataSourceHAGroups) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 			Unknown: false,
 			Null:    false,
 			Attrs: map[string]attr.Value{
-				"name":                 types.String{Null: true},
-				"id":                   types.String{Null: true},
-				"elasticsearch_url":    types.String{Null: true},
-				"elastic_index_prefix": types.String{Null: true},
+				"name":                     types.String{Null: true},
+				"id":                       types.String{Null: true},
+				"elasticsearch_url":        types.String{Null: true},
+				"elastic_index_prefix":     types.String{Null: true},
+				"elasticsearch_username":   types.String{Null: true},
+				"elasticsearch_shards":     types.Int64{Null: true},
+				"elasticsearch_replicas":   types.Int64{Null: true},
+				"elasticsearch_ca_cert":    types.String{Null: true},
+				"elasticsearch_verify_tls": types.Bool{Null: true},
 				"account_ids": types.Set{
 					Unknown:  false,
 					Null:     false,
@@ -123,36 +140,34 @@ func (r dataSourceHAGroups) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 				},
 			},
 			AttrTypes: map[string]attr.Type{
-				"name":                 types.StringType,
-				"id":                   types.StringType,
-				"elasticsearch_url":    types.StringType,
-				"elastic_index_prefix": types.StringType,
-				"account_ids":          types.SetType{ElemType: types.StringType},
-				"host_ids":             types.SetType{ElemType: types.StringType},
+				"name":                     types.StringType,
+				"id":                       types.StringType,
+				"elasticsearch_url":        types.StringType,
+				"elastic_index_prefix":     types.StringType,
+				"elasticsearch_username":   types.StringType,
+				"elasticsearch_shards":     types.Int64Type,
+				"elasticsearch_replicas":   types.Int64Type,
+				"elasticsearch_ca_cert":    types.StringType,
+				"elasticsearch_verify_tls": types.BoolType,
+				"account_ids":              types.SetType{ElemType: types.StringType},
+				"host_ids":                 types.SetType{ElemType: types.StringType},
 			},
 		}
 		// assign the values from the response to the object
-		groupName, ok := group["name"].(string)
-		if ok {
-			groupObject.Attrs["name"] = types.String{Value: groupName}
-		}
-		groupId, ok := group["id"].(string)
-		if ok {
-			groupObject.Attrs["id"] = types.String{Value: groupId}
-		}
-		groupElasticsearchAddress, ok := group["elasticsearchAddress"].(string)
-		if ok {
-			groupObject.Attrs["elasticsearch_url"] = types.String{Value: groupElasticsearchAddress}
-		}
-		groupElasticIndexPrefix, ok := group["elasticIndexPrefix"].(string)
-		if ok {
-			groupObject.Attrs["elastic_index_prefix"] = types.String{Value: groupElasticIndexPrefix}
-		}
-		groupAccountIds, ok := group["accountIds"].([]interface{})
-		if ok {
+		groupObject.Attrs["name"] = types.String{Value: group.Name}
+		groupObject.Attrs["id"] = types.String{Value: group.Id}
+		groupObject.Attrs["elasticsearch_url"] = types.String{Value: group.ElasticsearchAddress}
+		groupObject.Attrs["elastic_index_prefix"] = types.String{Value: group.ElasticIndexPrefix}
+		elastic := readHAGroupElastic(&group)
+		groupObject.Attrs["elasticsearch_username"] = elastic.Username
+		groupObject.Attrs["elasticsearch_shards"] = elastic.Shards
+		groupObject.Attrs["elasticsearch_replicas"] = elastic.Replicas
+		groupObject.Attrs["elasticsearch_ca_cert"] = elastic.CACert
+		groupObject.Attrs["elasticsearch_verify_tls"] = elastic.VerifyTLS
+		if group.AccountIds != nil {
 			var elems []attr.Value
-			for _, a := range groupAccountIds {
-				elems = append(elems, types.String{Value: a.(string)})
+			for _, a := range group.AccountIds {
+				elems = append(elems, types.String{Value: a})
 			}
 			groupObject.Attrs["account_ids"] = types.Set{
 				Unknown:  false,
@@ -161,11 +176,10 @@ func (r dataSourceHAGroups) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 				ElemType: types.StringType,
 			}
 		}
-		groupHostIds, ok := group["hostIds"].([]interface{})
-		if ok {
+		if group.HostIds != nil {
 			var elems []attr.Value
-			for _, h := range groupHostIds {
-				elems = append(elems, types.String{Value: h.(string)})
+			for _, h := range group.HostIds {
+				elems = append(elems, types.String{Value: h})
 			}
 
 			groupObject.Attrs["host_ids"] = types.Set{
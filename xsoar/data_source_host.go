@@ -2,9 +2,12 @@ package xsoar
 
 import (
 	"context"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"terraform-provider-xsoar/internal/client"
 	"time"
 )
 
@@ -63,45 +66,42 @@ type dataSourceHost struct {
 
 func (r dataSourceHost) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
 	// Declare struct that this function will set to this data source's config
-	var config Host
+	var config HostDataSource
 	diags := req.Config.Get(ctx, &config)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var host map[string]interface{}
-	var err error
-	c1 := make(chan map[string]interface{}, 1)
-	go func() {
-		for host == nil {
-			host, _, err = r.p.client.DefaultApi.GetHost(ctx, config.Name.Value).Execute()
-			if err != nil {
-				resp.Diagnostics.AddError(
-					"Error listing HA groups",
-					"Could not list HA groups: "+err.Error(),
-				)
-				return
-			}
-			time.Sleep(time.Second)
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_host", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var host *client.Host
+	err := resource.RetryContext(ctx, 300*time.Second, func() *resource.RetryError {
+		var getErr error
+		host, getErr = r.p.api.FindHost(ctx, config.Name.Value)
+		if getErr != nil {
+			return resource.NonRetryableError(getErr)
+		}
+		if host == nil {
+			return resource.RetryableError(fmt.Errorf("host %s is not registered", config.Name.Value))
 		}
-		c1 <- host
-	}()
-	select {
-	case _ = <-c1:
-		break
-	case <-time.After(300 * time.Second):
+		return nil
+	})
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting host",
-			"Could not get host before timeout",
+			"Could not get host: "+err.Error(),
 		)
 		return
 	}
 
 	// Map response body to resource schema attribute
-	var hostName = host["host"].(string)
-	var hostId = host["id"].(string)
-	var haGroupId = host["hostGroupId"].(string)
+	var hostName = host.Host
+	var hostId = host.Id
+	var haGroupId = host.HostGroupId
 
 	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, haGroupId).Execute()
 	if err != nil {
@@ -112,25 +112,25 @@ func (r dataSourceHost) Read(ctx context.Context, req tfsdk.ReadDataSourceReques
 		return
 	}
 
-	var result Host
-	result = Host{
+	var result HostDataSource
+	result = HostDataSource{
 		Name: types.String{Value: hostName},
 		Id:   types.String{Value: hostId},
 	}
 
 	var isHA = false
-	if host["host"].(string) != haGroup.GetName() {
+	if host.Host != haGroup.GetName() {
 		isHA = true
 		result.HAGroupName.Value = haGroup.GetName()
 	} else {
 		result.HAGroupName.Null = true
 	}
 
-	if len(host["elasticsearchAddress"].(string)) > 0 {
+	if len(host.ElasticsearchAddress) > 0 {
 		if isHA {
 			result.ElasticsearchUrl.Null = true
 		} else {
-			result.ElasticsearchUrl.Value = host["elasticsearchAddress"].(string)
+			result.ElasticsearchUrl.Value = host.ElasticsearchAddress
 		}
 	} else {
 		result.ElasticsearchUrl.Null = true
@@ -2,13 +2,10 @@ package xsoar
 
 import (
 	"context"
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
 	"log"
-	"net/http"
 )
 
 type dataSourceIntegrationInstanceType struct{}
@@ -72,20 +69,13 @@ func (r dataSourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadD
 		return
 	}
 
-	// Get resource from API
-	var integration map[string]interface{}
-	var httpResponse *http.Response
-	var err error
-	if config.Account.Null || len(config.Account.Value) == 0 {
-		integration, httpResponse, err = r.p.client.DefaultApi.GetIntegrationInstance(ctx).SetIdentifier(config.Name.Value).Execute()
-	} else {
-		integration, httpResponse, err = r.p.client.DefaultApi.GetIntegrationInstanceAccount(ctx, "acc_"+config.Account.Value).SetIdentifier(config.Name.Value).Execute()
-	}
-	if httpResponse != nil {
-		getBody := httpResponse.Body
-		b, _ := io.ReadAll(getBody)
-		log.Println(string(b))
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(config.Account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+
+	// Get resource from API
+	integration, err := r.p.api.FindIntegrationInstance(ctx, config.Account.Value, config.Name.Value)
 	if err != nil {
 		log.Println(err.Error())
 		resp.Diagnostics.AddError(
@@ -94,42 +84,16 @@ func (r dataSourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadD
 		)
 		return
 	}
-
-	var propagationLabels []attr.Value
-	if integration["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, prop := range integration["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   prop.(string),
-			})
-		}
+	if integration == nil {
+		resp.Diagnostics.AddError(
+			"Integration instance not found",
+			"Could not find integration instance: "+config.Name.Value,
+		)
+		return
 	}
 
 	// Map response body to resource schema attribute
-	result := IntegrationInstance{
-		Name:              types.String{Value: integration["name"].(string)},
-		Id:                types.String{Value: integration["id"].(string)},
-		IntegrationName:   types.String{Value: integration["brand"].(string)},
-		Account:           config.Account,
-		PropagationLabels: types.Set{Elems: propagationLabels, ElemType: types.StringType},
-		Config:            config.Config,
-	}
-
-	IncomingMapperId, ok := integration["incomingMapperId"].(string)
-	if ok {
-		result.IncomingMapperId = types.String{Value: IncomingMapperId}
-	} else {
-		result.IncomingMapperId = types.String{Null: true}
-	}
-	MappingId, ok := integration["mappingId"].(string)
-	if ok {
-		result.MappingId = types.String{Value: MappingId}
-	} else {
-		result.MappingId = types.String{Null: true}
-	}
+	result := integrationInstanceModel(integration, config.Account, config.Config)
 
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
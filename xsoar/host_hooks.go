@@ -0,0 +1,141 @@
+package xsoar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"io"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"time"
+)
+
+// hostLicensePath is where the server reads its license from
+const hostLicensePath = "/var/lib/demisto/demisto.lic"
+
+var fileModePattern = regexp.MustCompile(`^0?[0-7]{3}$`)
+
+// postInstallCommands returns the configured post-install commands in order
+func postInstallCommands(host Host) []string {
+	var commands []string
+	for _, elem := range host.PostInstallCommands.Elems {
+		if command, ok := elem.(types.String); ok && !command.Null && !command.Unknown {
+			commands = append(commands, command.Value)
+		}
+	}
+	return commands
+}
+
+// hasPostInstall reports whether any post-install step is configured
+func hasPostInstall(host Host) bool {
+	return (!host.LicenseFile.Null && len(host.LicenseFile.Value) > 0) || len(host.Files) > 0 || len(postInstallCommands(host)) > 0
+}
+
+// postInstallChecksum hashes everything the post-install steps depend on, including the content of the local files,
+// so that a changed file is noticed even when its path stays the same.
+func postInstallChecksum(host Host) (string, error) {
+	hash := sha256.New()
+	hashFile := func(source string) error {
+		f, err := os.Open(source)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(hash, f)
+		return err
+	}
+
+	if !host.LicenseFile.Null && len(host.LicenseFile.Value) > 0 {
+		fmt.Fprintf(hash, "license\x00")
+		if err := hashFile(host.LicenseFile.Value); err != nil {
+			return "", err
+		}
+	}
+	for _, file := range host.Files {
+		fmt.Fprintf(hash, "\x00file\x00%s\x00%s\x00%s\x00%s\x00", file.Source.Value, file.Destination.Value, file.Owner.Value, file.Mode.Value)
+		if err := hashFile(file.Source.Value); err != nil {
+			return "", err
+		}
+	}
+	for _, command := range postInstallCommands(host) {
+		fmt.Fprintf(hash, "\x00command\x00%s", command)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// runPostInstall copies the license and files to the host, restarts the server if the license changed, then runs the
+// post-install commands in order
+func runPostInstall(ctx context.Context, conn *sshConnection, host Host) error {
+	if !host.LicenseFile.Null && len(host.LicenseFile.Value) > 0 {
+		log.Println("Uploading license")
+		err := uploadHostFile(ctx, conn, host.LicenseFile.Value, hostLicensePath, "demisto:demisto", "0640")
+		if err != nil {
+			return fmt.Errorf("could not upload license: %s", err)
+		}
+	}
+
+	for _, file := range host.Files {
+		log.Printf("Uploading %s to %s\n", file.Source.Value, file.Destination.Value)
+		err := uploadHostFile(ctx, conn, file.Source.Value, file.Destination.Value, file.Owner.Value, file.Mode.Value)
+		if err != nil {
+			return fmt.Errorf("could not upload %s: %s", file.Source.Value, err)
+		}
+	}
+
+	if !host.LicenseFile.Null && len(host.LicenseFile.Value) > 0 {
+		err := runSSHCommand(ctx, conn, "restart", "sudo systemctl restart demisto")
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, command := range postInstallCommands(host) {
+		err := runSSHCommand(ctx, conn, fmt.Sprintf("post-install command %d", i+1), command)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadHostFile copies a local file to destination on the host, see uploadHostContent
+func uploadHostFile(ctx context.Context, conn *sshConnection, source string, destination string, owner string, mode string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return uploadHostContent(ctx, conn, f, info.Size(), destination, owner, mode)
+}
+
+// uploadHostContent writes content to destination on the host, creating its directory. The content is staged in /tmp
+// and moved into place with sudo, so destination can be anywhere. Owner and mode are left unchanged when empty.
+func uploadHostContent(ctx context.Context, conn *sshConnection, content io.Reader, size int64, destination string, owner string, mode string) error {
+	staging := fmt.Sprintf("/tmp/xsoar_upload_%d", time.Now().UnixNano())
+	err := uploadSSHFile(ctx, conn, content, size, staging, 0600)
+	if err != nil {
+		return err
+	}
+
+	cmd := fmt.Sprintf("sudo mkdir -p %s && sudo mv %s %s", shellQuote(path.Dir(destination)), shellQuote(staging), shellQuote(destination))
+	if len(owner) > 0 {
+		cmd += fmt.Sprintf(" && sudo chown %s %s", shellQuote(owner), shellQuote(destination))
+	}
+	if len(mode) > 0 {
+		cmd += fmt.Sprintf(" && sudo chmod %s %s", shellQuote(mode), shellQuote(destination))
+	}
+	err = runSSHCommand(ctx, conn, "install "+path.Base(destination), cmd)
+	if err != nil {
+		_ = runSSHCommand(ctx, conn, "cleanup", "rm -f "+shellQuote(staging))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package xsoar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// hostLockHeld is the exit status of the acquisition script when another installation holds the lock
+	hostLockHeld = 75
+	// hostLockPollInterval is the time between attempts to take a held lock
+	hostLockPollInterval = 10 * time.Second
+)
+
+// hostInstallSlots serialises installations sharing a lock within this provider process. Each value is a channel
+// with capacity one, holding a token while an installation is running.
+var hostInstallSlots sync.Map
+
+// hostInstallLock guards an installation onto shared storage. It is held both in-process and as a lock directory on
+// the shared volume, so installations from other provider processes are excluded as well.
+type hostInstallLock struct {
+	conn    *sshConnection
+	path    string
+	owner   string
+	slot    chan struct{}
+	release sync.Once
+}
+
+// acquireHostInstallLock waits up to timeout for the lock in nfsMount. A lock older than ttl is considered abandoned
+// and removed.
+func acquireHostInstallLock(ctx context.Context, conn *sshConnection, key string, nfsMount string, hostName string, timeout time.Duration, ttl time.Duration) (*hostInstallLock, error) {
+	deadline := time.After(timeout)
+
+	// in-process lock, no polling needed
+	slot, _ := hostInstallSlots.LoadOrStore(key, make(chan struct{}, 1))
+	lock := &hostInstallLock{
+		conn: conn,
+		path: nfsMount + "/xsoar_host_install.lock",
+		slot: slot.(chan struct{}),
+	}
+	select {
+	case lock.slot <- struct{}{}:
+	case <-deadline:
+		return nil, fmt.Errorf("timed out after %s waiting for another installation in this run to finish", timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	providerHost, _ := os.Hostname()
+	lock.owner = fmt.Sprintf("host=%s provider=%s pid=%d time=%s", hostName, providerHost, os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	script := hostLockAcquireScript(lock.path, lock.owner, ttl)
+
+	for {
+		err := runSSHCommand(ctx, conn, "lock acquisition", script)
+		if err == nil {
+			tflog.SubsystemInfo(withLogSubsystem(ctx, sshLogSubsystem), sshLogSubsystem, "acquired install lock", map[string]interface{}{
+				"path":  lock.path,
+				"owner": lock.owner,
+			})
+			return lock, nil
+		}
+		var cmdErr *sshCommandError
+		if !errors.As(err, &cmdErr) || cmdErr.ExitStatus != hostLockHeld {
+			<-lock.slot
+			return nil, err
+		}
+		select {
+		case <-time.After(hostLockPollInterval):
+		case <-deadline:
+			<-lock.slot
+			return nil, fmt.Errorf("timed out after %s waiting for lock %s: %s", timeout, lock.path, cmdErr.Error())
+		case <-ctx.Done():
+			<-lock.slot
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Release removes the lock directory if this installation still owns it, then frees the in-process lock. It is safe
+// to call more than once.
+func (l *hostInstallLock) Release(ctx context.Context) error {
+	var err error
+	l.release.Do(func() {
+		defer func() { <-l.slot }()
+		err = resource.RetryContext(ctx, time.Minute, func() *resource.RetryError {
+			cmdErr := runSSHCommand(ctx, l.conn, "lock release", hostLockReleaseScript(l.path, l.owner))
+			if cmdErr != nil {
+				return resource.RetryableError(cmdErr)
+			}
+			return nil
+		})
+	})
+	return err
+}
+
+// hostLockAcquireScript returns the script taking the lock directory at path for owner. It exits with hostLockHeld
+// when another installation holds the lock, after removing it if it is older than ttl.
+//
+// Only renames are used to change the lock, since they are atomic on NFS: a lock is prepared under a temporary name
+// with its owner file and renamed into place, which fails while another lock, never empty, is there. A stale lock is
+// marked by creating a takeover directory in it, which only one provider can do, and renamed away. The owner is
+// checked again after the rename, and a lock that was released and taken again in the meantime is put back.
+func hostLockAcquireScript(path string, owner string, ttl time.Duration) string {
+	return fmt.Sprintf(`lock=%s
+new="$lock.new.$$"
+sudo rm -rf "$new" && sudo mkdir "$new" && printf '%%s\n' %s | sudo tee "$new/owner" >/dev/null || exit 1
+if sudo mv -T "$new" "$lock" 2>/dev/null; then
+  exit 0
+fi
+sudo rm -rf "$new"
+held=$(cat "$lock/owner" 2>/dev/null)
+age=$(( $(date +%%s) - $(stat -c %%Y "$lock/owner" 2>/dev/null || stat -c %%Y "$lock" 2>/dev/null || date +%%s) ))
+if [ "$age" -gt %d ]; then
+  if sudo mkdir "$lock/takeover" 2>/dev/null; then
+    if [ "$(cat "$lock/owner" 2>/dev/null)" != "$held" ]; then
+      sudo rmdir "$lock/takeover"
+    elif sudo mv -T "$lock" "$lock.stale.$$" 2>/dev/null; then
+      if [ "$(cat "$lock.stale.$$/owner" 2>/dev/null)" = "$held" ]; then
+        echo "removed stale lock after ${age}s, held by: $held"
+        sudo rm -rf "$lock.stale.$$"
+      else
+        sudo rmdir "$lock.stale.$$/takeover" 2>/dev/null
+        sudo mv -T "$lock.stale.$$" "$lock"
+      fi
+    fi
+  else
+    echo "stale lock held for ${age}s by: $held is being removed by another installation"
+  fi
+else
+  echo "lock held for ${age}s by: $held"
+fi
+exit %d`, shellQuote(path), shellQuote(owner), int64(ttl.Seconds()), hostLockHeld)
+}
+
+// hostLockReleaseScript returns the script removing the lock directory at path if owner still holds it. The lock is
+// renamed away before it is removed, and put back if it turns out to belong to another installation.
+func hostLockReleaseScript(path string, owner string) string {
+	return fmt.Sprintf(`lock=%s
+owner=%s
+if [ "$(cat "$lock/owner" 2>/dev/null)" = "$owner" ] && sudo mv -T "$lock" "$lock.released.$$" 2>/dev/null; then
+  if [ "$(cat "$lock.released.$$/owner" 2>/dev/null)" = "$owner" ]; then
+    sudo rm -rf "$lock.released.$$"
+  else
+    sudo mv -T "$lock.released.$$" "$lock"
+  fi
+fi`, shellQuote(path), shellQuote(owner))
+}
@@ -0,0 +1,108 @@
+package xsoar
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// runLockScript runs a lock script locally, with sudo replaced by running the command directly
+func runLockScript(t *testing.T, script string) int {
+	out, err := exec.Command("sh", "-c", "sudo() { \"$@\"; }\n"+script).CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("could not run lock script: %s: %s", err, out)
+	}
+	return 0
+}
+
+func readLockOwner(t *testing.T, path string) string {
+	owner, err := os.ReadFile(filepath.Join(path, "owner"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(owner))
+}
+
+func TestHostLockScripts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xsoar_host_install.lock")
+	if status := runLockScript(t, hostLockAcquireScript(path, "owner1", time.Hour)); status != 0 {
+		t.Fatalf("acquisition exited with %d, want 0", status)
+	}
+	if owner := readLockOwner(t, path); owner != "owner1" {
+		t.Fatalf("lock is owned by %q, want owner1", owner)
+	}
+	if status := runLockScript(t, hostLockAcquireScript(path, "owner2", time.Hour)); status != hostLockHeld {
+		t.Fatalf("acquisition of a held lock exited with %d, want %d", status, hostLockHeld)
+	}
+
+	// only the owner removes the lock
+	runLockScript(t, hostLockReleaseScript(path, "owner2"))
+	if owner := readLockOwner(t, path); owner != "owner1" {
+		t.Fatalf("lock is owned by %q after release by another owner, want owner1", owner)
+	}
+	runLockScript(t, hostLockReleaseScript(path, "owner1"))
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("lock still exists after release: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 0 {
+		t.Fatalf("release left %d entries behind", len(entries))
+	}
+}
+
+// TestHostLockStaleTakeover checks that of several installations finding the same stale lock, exactly one gets the
+// lock afterwards
+func TestHostLockStaleTakeover(t *testing.T) {
+	for round := 0; round < 5; round++ {
+		path := filepath.Join(t.TempDir(), "xsoar_host_install.lock")
+		if status := runLockScript(t, hostLockAcquireScript(path, "crashed", time.Hour)); status != 0 {
+			t.Fatalf("acquisition exited with %d, want 0", status)
+		}
+		old := time.Now().Add(-2 * time.Hour)
+		if err := os.Chtimes(filepath.Join(path, "owner"), old, old); err != nil {
+			t.Fatal(err)
+		}
+
+		// every installation polls until it got the lock or saw another one take it
+		const installations = 8
+		var wg sync.WaitGroup
+		acquired := make(chan string, installations)
+		for i := 0; i < installations; i++ {
+			owner := "owner" + string(rune('a'+i))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for attempt := 0; attempt < 3; attempt++ {
+					if runLockScript(t, hostLockAcquireScript(path, owner, time.Hour)) == 0 {
+						acquired <- owner
+						return
+					}
+					if held := readLockOwner(t, path); held != "" && held != "crashed" {
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(acquired)
+		var owners []string
+		for owner := range acquired {
+			owners = append(owners, owner)
+		}
+		if len(owners) != 1 {
+			t.Fatalf("round %d: lock was acquired by %v, want exactly one installation", round, owners)
+		}
+		if held := readLockOwner(t, path); held != owners[0] {
+			t.Fatalf("round %d: lock is owned by %q, want %s", round, held, owners[0])
+		}
+	}
+}
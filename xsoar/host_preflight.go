@@ -0,0 +1,93 @@
+package xsoar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// preflightCheck is a command run on the host before installing. The check fails when the command exits with a
+// non-zero status, and the last line of its output explains why.
+type preflightCheck struct {
+	Name    string
+	Command string
+}
+
+// hostPreflightChecks returns the checks configured in the preflight block of the host
+func hostPreflightChecks(host Host) []preflightCheck {
+	if len(host.Preflight) == 0 {
+		return nil
+	}
+	config := host.Preflight[0]
+	var checks []preflightCheck
+
+	if !config.MinDiskGB.Null && config.MinDiskGB.Value > 0 {
+		// /var/lib/demisto does not exist before the first install, so check the volume it will be created on
+		checks = append(checks, preflightCheck{
+			Name: "disk space",
+			Command: fmt.Sprintf(`d=/var/lib/demisto; while [ ! -d "$d" ]; do d=$(dirname "$d"); done
+avail=$(df -Pk "$d" | awk 'NR==2 {print $4}')
+echo "$((avail / 1048576)) GB free on $d, need %d GB"
+[ "$avail" -ge %d ]`, config.MinDiskGB.Value, config.MinDiskGB.Value*1048576),
+		})
+	}
+	if !config.MinMemoryMB.Null && config.MinMemoryMB.Value > 0 {
+		checks = append(checks, preflightCheck{
+			Name: "memory",
+			Command: fmt.Sprintf(`total=$(awk '/^MemTotal:/ {print $2}' /proc/meminfo)
+echo "$((total / 1024)) MB memory, need %d MB"
+[ "$total" -ge %d ]`, config.MinMemoryMB.Value, config.MinMemoryMB.Value*1024),
+		})
+	}
+	if !config.MinCPUs.Null && config.MinCPUs.Value > 0 {
+		checks = append(checks, preflightCheck{
+			Name: "CPU count",
+			Command: fmt.Sprintf(`n=$(nproc)
+echo "$n CPUs, need %d"
+[ "$n" -ge %d ]`, config.MinCPUs.Value, config.MinCPUs.Value),
+		})
+	}
+	if config.RequireContainerRuntime.Value {
+		checks = append(checks, preflightCheck{
+			Name:    "container runtime",
+			Command: `command -v docker || command -v podman || { echo "neither docker nor podman found"; exit 1; }`,
+		})
+	}
+	if !config.AllowExistingInstallation.Value {
+		checks = append(checks, preflightCheck{
+			Name:    "existing installation",
+			Command: `if [ -e /usr/local/demisto ]; then echo "/usr/local/demisto already exists"; exit 1; fi`,
+		})
+	}
+	if !host.NFSMount.Null && (config.CheckNFSMount.Null || config.CheckNFSMount.Value) {
+		checks = append(checks, preflightCheck{
+			Name: "NFS mount",
+			Command: fmt.Sprintf(`d=%s
+if sudo touch "$d/.xsoar_preflight.$$" && sudo rm -f "$d/.xsoar_preflight.$$"; then exit 0; fi
+echo "$d is not writable"; exit 1`, shellQuote(host.NFSMount.Value)),
+		})
+	}
+	return checks
+}
+
+// runPreflightChecks runs every check, so all problems with the host are reported at once
+func runPreflightChecks(ctx context.Context, conn *sshConnection, checks []preflightCheck) error {
+	var failures []string
+	for _, check := range checks {
+		err := runSSHCommand(ctx, conn, "preflight "+check.Name, check.Command)
+		if err == nil {
+			continue
+		}
+		reason := err.Error()
+		var cmdErr *sshCommandError
+		if errors.As(err, &cmdErr) && len(cmdErr.Tail) > 0 {
+			reason = cmdErr.Tail[len(cmdErr.Tail)-1]
+		}
+		failures = append(failures, fmt.Sprintf("- %s: %s", check.Name, reason))
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d pre-flight checks failed:\n%s", len(failures), len(checks), strings.Join(failures, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package xsoar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// transferInstaller places an installer built by the main server at dest on the machine. In pull mode the machine
+// downloads it from downloadPath on the main server itself, in push mode the provider downloads it with download and
+// uploads it over SSH, then verifies its checksum on the machine.
+func transferInstaller(ctx context.Context, p provider, conn *sshConnection, mode string, downloadPath string, dest string, download func(context.Context) (*os.File, error)) error {
+	if mode != "push" {
+		// the headers, including the API key, are passed in a file readable only by the SSH user, so they show up
+		// neither on the machine's command line nor in the log
+		cfg := p.client.GetConfig()
+		header := http.Header{}
+		for name, value := range cfg.DefaultHeader {
+			header.Set(name, value)
+		}
+		if err := p.auth.apply(header); err != nil {
+			return err
+		}
+		var headers strings.Builder
+		for name := range header {
+			fmt.Fprintf(&headers, "%s: %s\n", name, header.Get(name))
+		}
+		suffix := time.Now().UnixNano()
+		caFile := fmt.Sprintf("/tmp/xsoar_ca_%d.pem", suffix)
+		options, caBundle, err := pullCurlOptions(*p.data, caFile)
+		if err != nil {
+			return err
+		}
+		headerFile := fmt.Sprintf("/tmp/xsoar_headers_%d", suffix)
+		err = uploadSSHFile(ctx, conn, strings.NewReader(headers.String()), int64(headers.Len()), headerFile, 0600)
+		if err != nil {
+			return fmt.Errorf("could not upload request headers: %s", err)
+		}
+		temporary := []string{headerFile}
+		if caBundle != "" {
+			err = uploadSSHFile(ctx, conn, strings.NewReader(caBundle), int64(len(caBundle)), caFile, 0600)
+			if err != nil {
+				_ = runSSHCommand(ctx, conn, "request headers removal", "rm -f "+shellQuote(headerFile))
+				return fmt.Errorf("could not upload CA certificates: %s", err)
+			}
+			temporary = append(temporary, caFile)
+		}
+		cmd := fmt.Sprintf(
+			"sudo curl -sf -o %s -H @%s %s; rc=$?; rm -f %s; [ $rc -eq 0 ] && "+
+				"sudo chmod +x %s",
+			shellQuote(dest), shellQuote(headerFile), shellJoin(append(options, strings.TrimSuffix(cfg.Servers[0].URL, "/")+downloadPath)), shellJoin(temporary), shellQuote(dest))
+		return runSSHCommand(ctx, conn, "installer download", cmd)
+	}
+
+	// the installer may take longer to download than request_timeout allows for an API call
+	installer, err := download(withoutRequestTimeout(ctx))
+	if err != nil {
+		return fmt.Errorf("could not download installer from main host: %s", err)
+	}
+	if installer == nil {
+		return fmt.Errorf("main host returned an empty installer")
+	}
+	defer os.Remove(installer.Name())
+	defer installer.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, installer)
+	if err != nil {
+		return fmt.Errorf("could not read installer: %s", err)
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if _, err = installer.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("could not read installer: %s", err)
+	}
+	tflog.SubsystemInfo(withLogSubsystem(ctx, sshLogSubsystem), sshLogSubsystem, "uploading installer", map[string]interface{}{
+		"bytes":  size,
+		"sha256": checksum,
+	})
+
+	upload := dest + ".upload"
+	err = runSSHCommand(ctx, conn, "installer upload preparation", "sudo rm -f "+shellQuote(upload))
+	if err != nil {
+		return err
+	}
+	err = uploadSSHFile(ctx, conn, installer, size, upload, 0700)
+	if err != nil {
+		return err
+	}
+	return runSSHCommand(ctx, conn, "installer checksum verification", fmt.Sprintf(
+		"echo '%s  %s' | sha256sum -c - && sudo mv -f %s %s && sudo chmod +x %s",
+		checksum, upload, shellQuote(upload), shellQuote(dest), shellQuote(dest),
+	))
+}
+
+// pullCurlOptions returns the curl options that apply the TLS and proxy settings of the provider to a download made on
+// the machine, and the CA certificates to place at caFile for it, if any. Client certificates are not copied to the
+// machine, so pull mode cannot be used with them.
+func pullCurlOptions(config providerData, caFile string) ([]string, string, error) {
+	if stringFromEnv(config.ClientCert, "DEMISTO_CLIENT_CERT") != "" || stringFromEnv(config.ClientKey, "DEMISTO_CLIENT_KEY") != "" {
+		return nil, "", fmt.Errorf("the main server requires a client certificate, which is not copied to the machine: set installer_transfer to \"push\"")
+	}
+
+	var options []string
+	if config.Insecure.Value {
+		options = append(options, "-k")
+	}
+
+	// curl uses the given CAs instead of the machine's bundle
+	var caBundle strings.Builder
+	if caCertFile := stringFromEnv(config.CACertFile, "DEMISTO_CA_CERT_FILE"); caCertFile != "" {
+		pem, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("could not read ca_cert_file: %s", err)
+		}
+		caBundle.Write(pem)
+		caBundle.WriteString("\n")
+	}
+	if caCertPEM := stringFromEnv(config.CACertPEM, "DEMISTO_CA_CERT_PEM"); caCertPEM != "" {
+		caBundle.WriteString(caCertPEM)
+		caBundle.WriteString("\n")
+	}
+	if caBundle.Len() > 0 {
+		options = append(options, "--cacert", caFile)
+	}
+
+	// without proxy_url the machine's own proxy environment applies
+	if !config.ProxyUrl.Null && len(config.ProxyUrl.Value) > 0 {
+		options = append(options, "--proxy", config.ProxyUrl.Value)
+		var noProxy []string
+		for _, elem := range config.NoProxy.Elems {
+			if host, ok := elem.(types.String); ok && !host.Null {
+				noProxy = append(noProxy, host.Value)
+			}
+		}
+		if len(noProxy) > 0 {
+			options = append(options, "--noproxy", strings.Join(noProxy, ","))
+		}
+	}
+	return options, caBundle.String(), nil
+}
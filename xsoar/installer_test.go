@@ -0,0 +1,78 @@
+package xsoar
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPullCurlOptions(t *testing.T) {
+	for _, name := range []string{"DEMISTO_CA_CERT_FILE", "DEMISTO_CA_CERT_PEM", "DEMISTO_CLIENT_CERT", "DEMISTO_CLIENT_KEY"} {
+		t.Setenv(name, "")
+	}
+	caCertFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caCertFile, []byte("FILE PEM"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	noProxy := types.List{ElemType: types.StringType, Elems: []attr.Value{types.String{Value: "localhost"}, types.String{Value: ".internal"}}}
+
+	tests := map[string]struct {
+		config       providerData
+		wantOptions  []string
+		wantCABundle string
+		wantErr      string
+	}{
+		"defaults": {
+			config: providerData{CACertFile: types.String{Null: true}, CACertPEM: types.String{Null: true}, ProxyUrl: types.String{Null: true}},
+		},
+		"insecure": {
+			config:      providerData{Insecure: types.Bool{Value: true}},
+			wantOptions: []string{"-k"},
+		},
+		"ca cert file and pem": {
+			config:       providerData{CACertFile: types.String{Value: caCertFile}, CACertPEM: types.String{Value: "INLINE PEM"}},
+			wantOptions:  []string{"--cacert", "/tmp/ca.pem"},
+			wantCABundle: "FILE PEM\nINLINE PEM\n",
+		},
+		"missing ca cert file": {
+			config:  providerData{CACertFile: types.String{Value: filepath.Join(t.TempDir(), "missing.pem")}},
+			wantErr: "could not read ca_cert_file",
+		},
+		"proxy": {
+			config:      providerData{ProxyUrl: types.String{Value: "http://proxy:3128"}},
+			wantOptions: []string{"--proxy", "http://proxy:3128"},
+		},
+		"proxy with exceptions": {
+			config:      providerData{ProxyUrl: types.String{Value: "http://proxy:3128"}, NoProxy: noProxy},
+			wantOptions: []string{"--proxy", "http://proxy:3128", "--noproxy", "localhost,.internal"},
+		},
+		"client certificate": {
+			config:  providerData{ClientCert: types.String{Value: "CERT"}, ClientKey: types.String{Value: "KEY"}},
+			wantErr: `set installer_transfer to "push"`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			options, caBundle, err := pullCurlOptions(test.config, "/tmp/ca.pem")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(options, test.wantOptions) {
+				t.Errorf("expected options %q, got %q", test.wantOptions, options)
+			}
+			if caBundle != test.wantCABundle {
+				t.Errorf("expected CA bundle %q, got %q", test.wantCABundle, caBundle)
+			}
+		})
+	}
+}
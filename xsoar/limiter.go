@@ -0,0 +1,111 @@
+package xsoar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// requestLimiter is shared by every request of a provider instance. It caps the number of requests in flight and
+// spaces requests out to a maximum rate, so that a high -parallelism does not overload the main server.
+type requestLimiter struct {
+	// slots holds one token per request in flight, nil when concurrency is not limited
+	slots chan struct{}
+	// interval is the minimum time between the start of two requests, 0 when the rate is not limited
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newRequestLimiter returns a limiter, or nil when neither limit is set
+func newRequestLimiter(maxConcurrent int64, perSecond float64) *requestLimiter {
+	if maxConcurrent <= 0 && perSecond <= 0 {
+		return nil
+	}
+	l := &requestLimiter{}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+// acquire waits until the request may be sent. The returned function gives the slot back and must be called once the
+// request is done.
+func (l *requestLimiter) acquire(ctx context.Context) (func(), error) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if l.slots != nil {
+			<-l.slots
+		}
+	}
+
+	if l.interval > 0 {
+		// reserve the next free start time, then wait for it
+		l.mu.Lock()
+		now := time.Now()
+		start := l.next
+		if start.Before(now) {
+			start = now
+		}
+		l.next = start.Add(l.interval)
+		l.mu.Unlock()
+
+		if wait := time.Until(start); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+	return release, nil
+}
+
+// limitTransport applies a requestLimiter to every request. A request keeps its slot until its response body is
+// closed, since the server is still busy sending it.
+type limitTransport struct {
+	next    http.RoundTripper
+	limiter *requestLimiter
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody gives the limiter slot back when the body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,207 @@
+package xsoar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Log subsystems of the provider. The level of each can be set on its own with TF_LOG_PROVIDER_XSOAR_<SUBSYSTEM>,
+// e.g. TF_LOG_PROVIDER_XSOAR_API=TRACE, otherwise it follows TF_LOG_PROVIDER and TF_LOG.
+const (
+	apiLogSubsystem = "api"
+	sshLogSubsystem = "ssh"
+)
+
+// redacted replaces every value the logs must not contain
+const redacted = "***"
+
+// maxLoggedBody is the number of bytes of a request or response body written to the log
+const maxLoggedBody = 64 * 1024
+
+var (
+	// sensitiveHeader matches headers carrying credentials, including ones set through extra_headers
+	sensitiveHeader = regexp.MustCompile(`(?i)auth|cookie|token|secret|pass|api[-_]?key|signature`)
+	// sensitiveField matches JSON keys whose values are credentials
+	sensitiveField = regexp.MustCompile(`(?i)pass|secret|token|api[-_]?key|authorization|credential|private[-_]?key|cookie|certificate`)
+)
+
+// sensitiveParamTypes are the types of integration parameters holding credentials: encrypted, authentication and
+// encrypted long text
+var sensitiveParamTypes = map[float64]bool{4: true, 9: true, 14: true}
+
+// apiBodyLogLevels are the values of the TF_LOG variables at which the api subsystem logs bodies. JSON is TRACE in
+// JSON format.
+var apiBodyLogLevels = map[string]bool{"TRACE": true, "JSON": true}
+
+// apiBodiesLogged reports whether the api subsystem logs at TRACE, where request and response bodies are written. Its
+// level is the first one set of TF_LOG_PROVIDER_XSOAR_API, TF_LOG_PROVIDER_XSOAR, TF_LOG_PROVIDER and TF_LOG, as
+// set up by terraform-plugin-log, which does not tell the level of a logger.
+func apiBodiesLogged() bool {
+	for _, name := range []string{"TF_LOG_PROVIDER_XSOAR_API", "TF_LOG_PROVIDER_XSOAR", "TF_LOG_PROVIDER", "TF_LOG"} {
+		if level := strings.TrimSpace(os.Getenv(name)); level != "" {
+			return apiBodyLogLevels[strings.ToUpper(level)]
+		}
+	}
+	return false
+}
+
+// withLogSubsystem returns a context that logs to the given subsystem
+func withLogSubsystem(ctx context.Context, subsystem string) context.Context {
+	return tflog.NewSubsystem(ctx, subsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_XSOAR", subsystem))
+}
+
+// loggingTransport writes every request to the main server and its response to the api log subsystem: method, URL,
+// status and headers at DEBUG, bodies at TRACE. Credentials are replaced with *** before anything is logged. Bodies
+// are only read for the log when it is at TRACE, see apiBodiesLogged.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := withLogSubsystem(req.Context(), apiLogSubsystem)
+	fields := map[string]interface{}{
+		"method": req.Method,
+		"url":    req.URL.Redacted(),
+	}
+
+	tflog.SubsystemDebug(ctx, apiLogSubsystem, "sending request", mergeFields(fields, map[string]interface{}{
+		"headers": redactHeaders(req.Header),
+	}))
+	logBodies := apiBodiesLogged()
+	if logBodies && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			content, _ := io.ReadAll(io.LimitReader(body, maxLoggedBody+1))
+			body.Close()
+			tflog.SubsystemTrace(ctx, apiLogSubsystem, "request body", mergeFields(fields, map[string]interface{}{
+				"body": redactBody(content, req.Header.Get("Content-Type")),
+			}))
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields["duration_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		tflog.SubsystemDebug(ctx, apiLogSubsystem, "request failed", mergeFields(fields, map[string]interface{}{
+			"error": err.Error(),
+		}))
+		return resp, err
+	}
+
+	fields["status"] = resp.StatusCode
+	tflog.SubsystemDebug(ctx, apiLogSubsystem, "received response", mergeFields(fields, map[string]interface{}{
+		"headers": redactHeaders(resp.Header),
+	}))
+	if logBodies && resp.Body != nil {
+		// only the start of the body is read, the rest is streamed to the caller unchanged
+		content, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody+1))
+		resp.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(content), resp.Body), Closer: resp.Body}
+		tflog.SubsystemTrace(ctx, apiLogSubsystem, "response body", mergeFields(fields, map[string]interface{}{
+			"body": redactBody(content, resp.Header.Get("Content-Type")),
+		}))
+	}
+	return resp, nil
+}
+
+// prefixedBody is a response body whose first bytes were already read for the log
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func mergeFields(fields map[string]interface{}, extra map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(fields)+len(extra))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+// redactHeaders returns the headers as a map for logging, with credentials replaced
+func redactHeaders(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if sensitiveHeader.MatchString(name) {
+			result[name] = redacted
+			continue
+		}
+		result[name] = strings.Join(values, ", ")
+	}
+	return result
+}
+
+// redactBody returns a body for logging. JSON bodies are logged with credentials replaced, other bodies (e.g. the
+// installers) and bodies too large to log are only described.
+func redactBody(content []byte, contentType string) string {
+	if len(content) == 0 {
+		return ""
+	}
+	if len(content) > maxLoggedBody {
+		return fmt.Sprintf("<more than %d bytes of %s>", maxLoggedBody, contentType)
+	}
+	var value interface{}
+	if err := json.Unmarshal(content, &value); err != nil {
+		return fmt.Sprintf("<%d bytes of %s>", len(content), contentType)
+	}
+	redactedContent, err := json.Marshal(redactJSON(value))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes of %s>", len(content), contentType)
+	}
+	return string(redactedContent)
+}
+
+// redactJSON replaces the values of sensitive keys, and the values of integration parameters holding credentials
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			if sensitiveField.MatchString(key) && elem != nil {
+				result[key] = redacted
+				continue
+			}
+			result[key] = redactJSON(elem)
+		}
+		if isSensitiveParam(v) {
+			for _, key := range []string{"value", "defaultValue"} {
+				if _, ok := result[key]; ok {
+					result[key] = redacted
+				}
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			result[i] = redactJSON(elem)
+		}
+		return result
+	}
+	return value
+}
+
+// isSensitiveParam reports whether an object is an integration parameter holding a credential, judged by its type or
+// its name
+func isSensitiveParam(object map[string]interface{}) bool {
+	if paramType, ok := object["type"].(float64); ok && sensitiveParamTypes[paramType] {
+		return true
+	}
+	for _, key := range []string{"name", "display"} {
+		if name, ok := object[key].(string); ok && sensitiveField.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
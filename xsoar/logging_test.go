@@ -0,0 +1,125 @@
+package xsoar
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{
+		"Authorization":   []string{"C2B8A7F4E1D6"},
+		"X-Xdr-Auth-Id":   []string{"12"},
+		"X-Xdr-Nonce":     []string{"abcdef"},
+		"X-Xdr-Timestamp": []string{"1700000000000"},
+		"Cookie":          []string{"session=1"},
+		"X-Xsrf-Token":    []string{"token"},
+		"X-Api-Key":       []string{"key"},
+		"Accept":          []string{"application/json"},
+		"Content-Type":    []string{"application/json"},
+	}
+	got := redactHeaders(header)
+	for _, name := range []string{"Authorization", "X-Xdr-Auth-Id", "Cookie", "X-Xsrf-Token", "X-Api-Key"} {
+		if got[name] != redacted {
+			t.Errorf("header %s was logged as %q", name, got[name])
+		}
+	}
+	for _, name := range []string{"Accept", "Content-Type", "X-Xdr-Nonce", "X-Xdr-Timestamp"} {
+		if got[name] != header.Get(name) {
+			t.Errorf("header %s was logged as %q, want %q", name, got[name], header.Get(name))
+		}
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	body := `{
+		"name": "tenant1",
+		"password": "hunter2",
+		"apikey": "C2B8A7F4E1D6",
+		"nested": {"elasticsearchPassword": "hunter2", "host": "es"},
+		"data": [
+			{"name": "url", "type": 0, "value": "https://example.com"},
+			{"name": "apikey_field", "type": 4, "value": "encrypted"},
+			{"name": "credentials", "type": 9, "value": {"identifier": "user", "password": "hunter2"}},
+			{"name": "private", "type": 14, "value": "-----BEGIN", "defaultValue": "-----BEGIN"},
+			{"name": "login", "display": "Password", "type": 0, "value": "hunter2"}
+		]
+	}`
+	got := redactBody([]byte(body), "application/json")
+	for _, secret := range []string{"hunter2", "C2B8A7F4E1D6", "encrypted", "-----BEGIN"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("%q was logged in %s", secret, got)
+		}
+	}
+	for _, kept := range []string{`"name":"tenant1"`, `"host":"es"`, `"value":"https://example.com"`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("%s is missing from %s", kept, got)
+		}
+	}
+}
+
+func TestRedactBodyNotJSON(t *testing.T) {
+	if got := redactBody([]byte("#!/bin/sh\necho password"), "application/octet-stream"); strings.Contains(got, "password") {
+		t.Errorf("a body that is not JSON was logged: %s", got)
+	}
+	if got := redactBody(nil, "application/json"); got != "" {
+		t.Errorf("an empty body was logged as %q", got)
+	}
+}
+
+func TestApiBodiesLogged(t *testing.T) {
+	tests := map[string]struct {
+		env  map[string]string
+		want bool
+	}{
+		"unset":              {env: map[string]string{}, want: false},
+		"debug":              {env: map[string]string{"TF_LOG": "DEBUG"}, want: false},
+		"trace":              {env: map[string]string{"TF_LOG": "trace"}, want: true},
+		"json":               {env: map[string]string{"TF_LOG": "JSON"}, want: true},
+		"provider overrides": {env: map[string]string{"TF_LOG": "TRACE", "TF_LOG_PROVIDER": "INFO"}, want: false},
+		"subsystem trace":    {env: map[string]string{"TF_LOG": "INFO", "TF_LOG_PROVIDER_XSOAR_API": "TRACE"}, want: true},
+		"subsystem off":      {env: map[string]string{"TF_LOG_PROVIDER_XSOAR": "TRACE", "TF_LOG_PROVIDER_XSOAR_API": "OFF"}, want: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, variable := range []string{"TF_LOG_PROVIDER_XSOAR_API", "TF_LOG_PROVIDER_XSOAR", "TF_LOG_PROVIDER", "TF_LOG"} {
+				t.Setenv(variable, test.env[variable])
+			}
+			if got := apiBodiesLogged(); got != test.want {
+				t.Fatalf("apiBodiesLogged() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoggingTransportReadsBodyOnlyAtTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer server.Close()
+	transport := &loggingTransport{next: http.DefaultTransport}
+	for level, wantPrefixed := range map[string]bool{"DEBUG": false, "TRACE": true} {
+		t.Run(level, func(t *testing.T) {
+			for _, variable := range []string{"TF_LOG_PROVIDER_XSOAR_API", "TF_LOG_PROVIDER_XSOAR", "TF_LOG_PROVIDER"} {
+				t.Setenv(variable, "")
+			}
+			t.Setenv("TF_LOG", level)
+			req := httptest.NewRequest(http.MethodGet, server.URL+"/user", nil)
+			req.RequestURI = ""
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if _, prefixed := resp.Body.(*prefixedBody); prefixed != wantPrefixed {
+				t.Fatalf("body read for the log: %t, want %t", prefixed, wantPrefixed)
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil || string(body) != `{"id":"1"}` {
+				t.Fatalf("unexpected body %q, %v", body, err)
+			}
+		})
+	}
+}
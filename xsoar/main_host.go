@@ -0,0 +1,272 @@
+package xsoar
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"terraform-provider-xsoar/internal/client"
+	"time"
+)
+
+// mainHostSession talks to a main server that has no API key yet. It keeps the session and XSRF cookies the web
+// login relies on, the same way .run/provision_main.sh does with curl's cookie jar.
+type mainHostSession struct {
+	baseUrl  string
+	client   *http.Client
+	user     string
+	password string
+}
+
+func newMainHostSession(baseUrl string, insecure bool) (*mainHostSession, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure {
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := &http.Client{Jar: jar, Timeout: 60 * time.Second, Transport: &loggingTransport{next: tr}}
+	return &mainHostSession{baseUrl: strings.TrimSuffix(baseUrl, "/"), client: client}, nil
+}
+
+// xsrfToken returns the XSRF token cookie set by the server, which has to be echoed in the X-XSRF-TOKEN header
+func (s *mainHostSession) xsrfToken() string {
+	u, err := url.Parse(s.baseUrl)
+	if err != nil {
+		return ""
+	}
+	for _, cookie := range s.client.Jar.Cookies(u) {
+		if cookie.Name == "XSRF-TOKEN" {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// do sends a request and returns the response status and body. The API key is sent as the Authorization header when
+// set, otherwise the session cookies, and the credentials of the last successful login, authenticate the request.
+func (s *mainHostSession) do(ctx context.Context, method string, path string, apiKey string, body interface{}) (int, []byte, error) {
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.baseUrl+path, reqBody)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := s.xsrfToken(); len(token) > 0 {
+		req.Header.Set("X-XSRF-TOKEN", token)
+	}
+	if len(apiKey) > 0 {
+		req.Header.Set("Authorization", apiKey)
+	} else if len(s.user) > 0 {
+		req.SetBasicAuth(s.user, s.password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, respBody, err
+}
+
+// waitForHealth polls /health until the server answers, which also sets the XSRF cookie
+func (s *mainHostSession) waitForHealth(ctx context.Context, timeout time.Duration) error {
+	return resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		status, _, err := s.do(ctx, http.MethodGet, "/health", "", nil)
+		if err != nil {
+			return resource.RetryableError(err)
+		}
+		if status != http.StatusOK {
+			return resource.RetryableError(fmt.Errorf("/health returned status %d", status))
+		}
+		return nil
+	})
+}
+
+// apiKeyValid reports whether the server accepts apiKey
+func (s *mainHostSession) apiKeyValid(ctx context.Context, apiKey string) (bool, error) {
+	status, body, err := s.do(ctx, http.MethodGet, "/user", apiKey, nil)
+	if err != nil {
+		return false, err
+	}
+	switch status {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil
+	}
+	return false, fmt.Errorf("GET /user returned status %d: %s", status, string(body))
+}
+
+// login signs in to the web session. When newPassword is set the expired initial password is changed to it as part of
+// the login, as the first login of a fresh server requires.
+func (s *mainHostSession) login(ctx context.Context, user string, password string, newPassword string) error {
+	payload := map[string]interface{}{
+		"user":     user,
+		"password": password,
+	}
+	if len(newPassword) > 0 {
+		payload["newPassword"] = newPassword
+		payload["passwordValidator"] = newPassword
+		payload["loginFailed"] = true
+		payload["passwordExpired"] = true
+	}
+	status, body, err := s.do(ctx, http.MethodPost, "/login", "", payload)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("POST /login returned status %d: %s", status, string(body))
+	}
+	s.user = user
+	s.password = password
+	if len(newPassword) > 0 {
+		s.password = newPassword
+	}
+	return nil
+}
+
+// serverVersion returns the version the server reports in /about
+func (s *mainHostSession) serverVersion(ctx context.Context, apiKey string) (string, error) {
+	status, body, err := s.do(ctx, http.MethodGet, "/about", apiKey, nil)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("GET /about returned status %d", status)
+	}
+	var about client.About
+	if err := json.Unmarshal(body, &about); err != nil {
+		return "", fmt.Errorf("could not decode /about: %s", err)
+	}
+	return about.ServerVersion(), nil
+}
+
+// createAPIKey creates an API key with the given name and value. A key with the same name but another value is not
+// replaced. The session must be logged in.
+func (s *mainHostSession) createAPIKey(ctx context.Context, name string, apiKey string) error {
+	status, body, err := s.do(ctx, http.MethodGet, "/apikeys", "", nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		// the body is not included, it may list existing keys
+		return fmt.Errorf("GET /apikeys returned status %d", status)
+	}
+	keys, err := client.DecodeAPIKeys(body)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key.Name == name {
+			return fmt.Errorf("an API key named %s already exists with a different value, revoke it or choose another api_key_name", name)
+		}
+	}
+
+	status, body, err = s.do(ctx, http.MethodPost, "/apikeys", "", map[string]interface{}{
+		"name":   name,
+		"apikey": apiKey,
+	})
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("POST /apikeys returned status %d: %s", status, string(body))
+	}
+	return nil
+}
+
+// bootstrapMainHost waits for the main server to come up, sets the admin password on its first login and creates the
+// API key. Every step is skipped when it was already done, so it can be run again against a bootstrapped server.
+func bootstrapMainHost(ctx context.Context, host MainHost) error {
+	s, err := newMainHostSession(host.Url.Value, host.Insecure.Value)
+	if err != nil {
+		return err
+	}
+	timeout := 900 * time.Second
+	if !host.HealthTimeout.Null {
+		timeout = time.Duration(host.HealthTimeout.Value) * time.Second
+	}
+	ctx = withLogSubsystem(ctx, apiLogSubsystem)
+	tflog.SubsystemInfo(ctx, apiLogSubsystem, "waiting for the main server to become healthy", map[string]interface{}{
+		"url": host.Url.Value,
+	})
+	if err := s.waitForHealth(ctx, timeout); err != nil {
+		return fmt.Errorf("main server did not become healthy: %s", err)
+	}
+
+	valid, err := s.apiKeyValid(ctx, host.ApiKey.Value)
+	if err != nil {
+		return err
+	}
+	if valid {
+		tflog.SubsystemInfo(ctx, apiLogSubsystem, "API key is already accepted by the main server")
+		return nil
+	}
+
+	user := host.AdminUsername.Value
+	if host.AdminUsername.Null || len(user) == 0 {
+		user = "admin"
+	}
+	initialPassword := host.InitialPassword.Value
+	if host.InitialPassword.Null || len(initialPassword) == 0 {
+		initialPassword = "admin"
+	}
+	// a fresh server only accepts the initial password together with a new one, after that only the new one works
+	err = s.login(ctx, user, initialPassword, host.AdminPassword.Value)
+	if err != nil {
+		tflog.SubsystemInfo(ctx, apiLogSubsystem, "could not change the initial password, logging in with admin_password", map[string]interface{}{
+			"error": err.Error(),
+		})
+		if err := s.login(ctx, user, host.AdminPassword.Value, ""); err != nil {
+			return fmt.Errorf("could not log in as %s: %s", user, err)
+		}
+	}
+
+	// older servers do not take an API key with a value chosen by the client
+	version, err := s.serverVersion(ctx, "")
+	if err != nil {
+		tflog.SubsystemWarn(ctx, apiLogSubsystem, "could not read the version of the main server", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if err := checkServerVersion("xsoar_main_host", apiKeyMinVersion, version); err != nil {
+		return err
+	}
+
+	name := host.ApiKeyName.Value
+	if host.ApiKeyName.Null || len(name) == 0 {
+		name = "terraform"
+	}
+	if err := s.createAPIKey(ctx, name, host.ApiKey.Value); err != nil {
+		return fmt.Errorf("could not create API key: %s", err)
+	}
+	valid, err = s.apiKeyValid(ctx, host.ApiKey.Value)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("API key %s was created but is not accepted by the main server", name)
+	}
+	return nil
+}
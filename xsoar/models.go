@@ -23,12 +23,34 @@ type Accounts struct {
 
 // HAGroup -
 type HAGroup struct {
-	Name               types.String `tfsdk:"name"`
-	Id                 types.String `tfsdk:"id"`
-	ElasticsearchUrl   types.String `tfsdk:"elasticsearch_url"`
-	ElasticIndexPrefix types.String `tfsdk:"elastic_index_prefix"`
-	AccountIds         types.Set    `tfsdk:"account_ids"`
-	HostIds            types.Set    `tfsdk:"host_ids"`
+	Name                   types.String `tfsdk:"name"`
+	Id                     types.String `tfsdk:"id"`
+	ElasticsearchUrl       types.String `tfsdk:"elasticsearch_url"`
+	ElasticIndexPrefix     types.String `tfsdk:"elastic_index_prefix"`
+	ElasticsearchUsername  types.String `tfsdk:"elasticsearch_username"`
+	ElasticsearchPassword  types.String `tfsdk:"elasticsearch_password"`
+	ElasticsearchApiKey    types.String `tfsdk:"elasticsearch_api_key"`
+	ElasticsearchShards    types.Int64  `tfsdk:"elasticsearch_shards"`
+	ElasticsearchReplicas  types.Int64  `tfsdk:"elasticsearch_replicas"`
+	ElasticsearchCACert    types.String `tfsdk:"elasticsearch_ca_cert"`
+	ElasticsearchVerifyTLS types.Bool   `tfsdk:"elasticsearch_verify_tls"`
+	AccountIds             types.Set    `tfsdk:"account_ids"`
+	HostIds                types.Set    `tfsdk:"host_ids"`
+}
+
+// HAGroupDataSource -
+type HAGroupDataSource struct {
+	Name                   types.String `tfsdk:"name"`
+	Id                     types.String `tfsdk:"id"`
+	ElasticsearchUrl       types.String `tfsdk:"elasticsearch_url"`
+	ElasticIndexPrefix     types.String `tfsdk:"elastic_index_prefix"`
+	ElasticsearchUsername  types.String `tfsdk:"elasticsearch_username"`
+	ElasticsearchShards    types.Int64  `tfsdk:"elasticsearch_shards"`
+	ElasticsearchReplicas  types.Int64  `tfsdk:"elasticsearch_replicas"`
+	ElasticsearchCACert    types.String `tfsdk:"elasticsearch_ca_cert"`
+	ElasticsearchVerifyTLS types.Bool   `tfsdk:"elasticsearch_verify_tls"`
+	AccountIds             types.Set    `tfsdk:"account_ids"`
+	HostIds                types.Set    `tfsdk:"host_ids"`
 }
 
 // HAGroups -
@@ -40,16 +62,101 @@ type HAGroups struct {
 
 // Host -
 type Host struct {
-	Name                types.String `tfsdk:"name"`
-	Id                  types.String `tfsdk:"id"`
-	HAGroupName         types.String `tfsdk:"ha_group_name"`
-	NFSMount            types.String `tfsdk:"nfs_mount"`
-	ElasticsearchUrl    types.String `tfsdk:"elasticsearch_url"`
-	ServerUrl           types.String `tfsdk:"server_url"`
-	SSHUser             types.String `tfsdk:"ssh_user"`
-	SSHKey              types.String `tfsdk:"ssh_key"`
-	InstallationTimeout types.Int64  `tfsdk:"installation_timeout"`
-	ExtraFlags          types.List   `tfsdk:"extra_flags"`
+	Name                   types.String       `tfsdk:"name"`
+	Id                     types.String       `tfsdk:"id"`
+	HAGroupName            types.String       `tfsdk:"ha_group_name"`
+	NFSMount               types.String       `tfsdk:"nfs_mount"`
+	ElasticsearchUrl       types.String       `tfsdk:"elasticsearch_url"`
+	ElasticsearchUsername  types.String       `tfsdk:"elasticsearch_username"`
+	ElasticsearchPassword  types.String       `tfsdk:"elasticsearch_password"`
+	ElasticsearchApiKey    types.String       `tfsdk:"elasticsearch_api_key"`
+	ElasticIndexPrefix     types.String       `tfsdk:"elastic_index_prefix"`
+	ElasticsearchCACert    types.String       `tfsdk:"elasticsearch_ca_cert"`
+	ElasticsearchVerifyTLS types.Bool         `tfsdk:"elasticsearch_verify_tls"`
+	ServerUrl              types.String       `tfsdk:"server_url"`
+	SSHUser                types.String       `tfsdk:"ssh_user"`
+	SSHKey                 types.String       `tfsdk:"ssh_key"`
+	SSHKeyPassphrase       types.String       `tfsdk:"ssh_key_passphrase"`
+	SSHCertificate         types.String       `tfsdk:"ssh_certificate"`
+	SSHPassword            types.String       `tfsdk:"ssh_password"`
+	SSHUseAgent            types.Bool         `tfsdk:"ssh_use_agent"`
+	HostKey                types.String       `tfsdk:"host_key"`
+	KnownHostsFile         types.String       `tfsdk:"known_hosts_file"`
+	ObservedHostKey        types.String       `tfsdk:"observed_host_key"`
+	ServerVersion          types.String       `tfsdk:"server_version"`
+	TargetVersion          types.String       `tfsdk:"target_version"`
+	Status                 types.String       `tfsdk:"status"`
+	LastHeartbeat          types.String       `tfsdk:"last_heartbeat"`
+	Accounts               types.Set          `tfsdk:"accounts"`
+	CheckService           types.Bool         `tfsdk:"check_service"`
+	ServiceStatus          types.String       `tfsdk:"service_status"`
+	DestroyMode            types.String       `tfsdk:"destroy_mode"`
+	AccountsOnDestroy      types.String       `tfsdk:"accounts_on_destroy"`
+	MoveAccountsTo         types.String       `tfsdk:"move_accounts_to"`
+	InstallationTimeout    types.Int64        `tfsdk:"installation_timeout"`
+	LockTimeout            types.Int64        `tfsdk:"lock_timeout"`
+	LockTTL                types.Int64        `tfsdk:"lock_ttl"`
+	ExtraFlags             types.List         `tfsdk:"extra_flags"`
+	InstallerTransfer      types.String       `tfsdk:"installer_transfer"`
+	InstallerOptions       []InstallerOptions `tfsdk:"installer_options"`
+	LicenseFile            types.String       `tfsdk:"license_file"`
+	Files                  []HostFile         `tfsdk:"files"`
+	PostInstallCommands    types.List         `tfsdk:"post_install_commands"`
+	PostInstallChecksum    types.String       `tfsdk:"post_install_checksum"`
+	Preflight              []PreflightChecks  `tfsdk:"preflight"`
+	Bastions               []Bastion          `tfsdk:"bastion"`
+}
+
+// InstallerOptions -
+type InstallerOptions struct {
+	TempFolder      types.String `tfsdk:"temp_folder"`
+	ExternalAddress types.String `tfsdk:"external_address"`
+	Tools           types.Bool   `tfsdk:"tools"`
+	Docker          types.Bool   `tfsdk:"docker"`
+	Offline         types.Bool   `tfsdk:"offline"`
+	ExtraFlags      types.List   `tfsdk:"extra_flags"`
+}
+
+// HostFile -
+type HostFile struct {
+	Source      types.String `tfsdk:"source"`
+	Destination types.String `tfsdk:"destination"`
+	Owner       types.String `tfsdk:"owner"`
+	Mode        types.String `tfsdk:"mode"`
+}
+
+// PreflightChecks -
+type PreflightChecks struct {
+	MinDiskGB                 types.Int64 `tfsdk:"min_disk_gb"`
+	MinMemoryMB               types.Int64 `tfsdk:"min_memory_mb"`
+	MinCPUs                   types.Int64 `tfsdk:"min_cpus"`
+	RequireContainerRuntime   types.Bool  `tfsdk:"require_container_runtime"`
+	AllowExistingInstallation types.Bool  `tfsdk:"allow_existing_installation"`
+	CheckNFSMount             types.Bool  `tfsdk:"check_nfs_mount"`
+}
+
+// Bastion -
+type Bastion struct {
+	Host             types.String `tfsdk:"host"`
+	User             types.String `tfsdk:"user"`
+	SSHKey           types.String `tfsdk:"ssh_key"`
+	SSHKeyPassphrase types.String `tfsdk:"ssh_key_passphrase"`
+	SSHCertificate   types.String `tfsdk:"ssh_certificate"`
+	SSHPassword      types.String `tfsdk:"ssh_password"`
+	SSHUseAgent      types.Bool   `tfsdk:"ssh_use_agent"`
+	HostKey          types.String `tfsdk:"host_key"`
+	ObservedHostKey  types.String `tfsdk:"observed_host_key"`
+}
+
+// HostDataSource -
+type HostDataSource struct {
+	Name             types.String `tfsdk:"name"`
+	Id               types.String `tfsdk:"id"`
+	HAGroupName      types.String `tfsdk:"ha_group_name"`
+	ElasticsearchUrl types.String `tfsdk:"elasticsearch_url"`
+	ServerUrl        types.String `tfsdk:"server_url"`
+	SSHUser          types.String `tfsdk:"ssh_user"`
+	SSHKey           types.String `tfsdk:"ssh_key"`
 }
 
 // IntegrationInstance -
@@ -84,3 +191,58 @@ type Mapper struct {
 	Account           types.String `tfsdk:"account"`
 	Direction         types.String `tfsdk:"direction"`
 }
+
+// Engine -
+type Engine struct {
+	Name                types.String `tfsdk:"name"`
+	Id                  types.String `tfsdk:"id"`
+	Status              types.String `tfsdk:"status"`
+	ServerUrl           types.String `tfsdk:"server_url"`
+	SSHUser             types.String `tfsdk:"ssh_user"`
+	SSHKey              types.String `tfsdk:"ssh_key"`
+	SSHKeyPassphrase    types.String `tfsdk:"ssh_key_passphrase"`
+	SSHCertificate      types.String `tfsdk:"ssh_certificate"`
+	SSHPassword         types.String `tfsdk:"ssh_password"`
+	SSHUseAgent         types.Bool   `tfsdk:"ssh_use_agent"`
+	HostKey             types.String `tfsdk:"host_key"`
+	KnownHostsFile      types.String `tfsdk:"known_hosts_file"`
+	ObservedHostKey     types.String `tfsdk:"observed_host_key"`
+	InstallationTimeout types.Int64  `tfsdk:"installation_timeout"`
+	InstallerTransfer   types.String `tfsdk:"installer_transfer"`
+	DestroyMode         types.String `tfsdk:"destroy_mode"`
+	Bastions            []Bastion    `tfsdk:"bastion"`
+}
+
+// EngineGroup -
+type EngineGroup struct {
+	Name      types.String `tfsdk:"name"`
+	Id        types.String `tfsdk:"id"`
+	EngineIds types.Set    `tfsdk:"engine_ids"`
+}
+
+// Engines -
+type Engines struct {
+	Name    types.String `tfsdk:"name"`
+	Engines types.Set    `tfsdk:"engines"`
+}
+
+// MainHost -
+type MainHost struct {
+	Url             types.String `tfsdk:"url"`
+	Id              types.String `tfsdk:"id"`
+	AdminUsername   types.String `tfsdk:"admin_username"`
+	InitialPassword types.String `tfsdk:"initial_password"`
+	AdminPassword   types.String `tfsdk:"admin_password"`
+	ApiKey          types.String `tfsdk:"api_key"`
+	ApiKeyName      types.String `tfsdk:"api_key_name"`
+	Insecure        types.Bool   `tfsdk:"insecure"`
+	HealthTimeout   types.Int64  `tfsdk:"health_timeout"`
+}
+
+// ApiKey -
+type ApiKey struct {
+	Name    types.String `tfsdk:"name"`
+	Id      types.String `tfsdk:"id"`
+	Value   types.String `tfsdk:"value"`
+	Account types.String `tfsdk:"account"`
+}
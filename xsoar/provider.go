@@ -3,12 +3,20 @@ package xsoar
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"github.com/badarsebard/xsoar-sdk-go/openapi"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/net/http/httpproxy"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"terraform-provider-xsoar/internal/client"
+	"time"
 )
 
 var _ = os.Stderr
@@ -23,6 +31,10 @@ type provider struct {
 	configured bool
 	client     *openapi.APIClient
 	data       *providerData
+	auth       *apiAuth
+	server     *serverInfo
+	// api is the typed layer over client used for records the API client returns as untyped maps
+	api *client.Client
 }
 
 func (p *provider) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
@@ -36,10 +48,87 @@ func (p *provider) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics)
 				Type:     types.StringType,
 				Optional: true,
 			},
+			"api_key_id": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"auth_type": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"product": {
+				Type:     types.StringType,
+				Optional: true,
+			},
 			"insecure": {
 				Type:     types.BoolType,
 				Optional: true,
 			},
+			"ca_cert_file": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"ca_cert_pem": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"client_cert": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"client_key": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"tls_server_name": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"proxy_url": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"no_proxy": {
+				Type:     types.ListType{ElemType: types.StringType},
+				Optional: true,
+			},
+			"extra_headers": {
+				Type:     types.MapType{ElemType: types.StringType},
+				Optional: true,
+			},
+			"request_timeout": {
+				Type:     types.Int64Type,
+				Optional: true,
+			},
+			"max_concurrent_requests": {
+				Type:     types.Int64Type,
+				Optional: true,
+			},
+			"requests_per_second": {
+				Type:     types.Float64Type,
+				Optional: true,
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"retry": {
+				NestingMode: tfsdk.BlockNestingModeList,
+				MaxItems:    1,
+				Attributes: map[string]tfsdk.Attribute{
+					"max_attempts": {
+						Type:     types.Int64Type,
+						Optional: true,
+					},
+					"min_wait": {
+						Type:     types.Int64Type,
+						Optional: true,
+					},
+					"max_wait": {
+						Type:     types.Int64Type,
+						Optional: true,
+					},
+				},
+			},
 		},
 	}, nil
 }
@@ -47,8 +136,33 @@ func (p *provider) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics)
 // Provider schema struct
 type providerData struct {
 	Apikey   types.String `tfsdk:"api_key"`
+	ApikeyId types.String `tfsdk:"api_key_id"`
+	AuthType types.String `tfsdk:"auth_type"`
+	Product  types.String `tfsdk:"product"`
 	MainHost types.String `tfsdk:"main_host"`
 	Insecure types.Bool   `tfsdk:"insecure"`
+
+	CACertFile    types.String `tfsdk:"ca_cert_file"`
+	CACertPEM     types.String `tfsdk:"ca_cert_pem"`
+	ClientCert    types.String `tfsdk:"client_cert"`
+	ClientKey     types.String `tfsdk:"client_key"`
+	TLSServerName types.String `tfsdk:"tls_server_name"`
+
+	ProxyUrl       types.String `tfsdk:"proxy_url"`
+	NoProxy        types.List   `tfsdk:"no_proxy"`
+	ExtraHeaders   types.Map    `tfsdk:"extra_headers"`
+	RequestTimeout types.Int64  `tfsdk:"request_timeout"`
+
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
+
+	Retry []providerRetry `tfsdk:"retry"`
+}
+
+type providerRetry struct {
+	MaxAttempts types.Int64 `tfsdk:"max_attempts"`
+	MinWait     types.Int64 `tfsdk:"min_wait"`
+	MaxWait     types.Int64 `tfsdk:"max_wait"`
 }
 
 func (p *provider) Configure(ctx context.Context, req tfsdk.ConfigureProviderRequest, resp *tfsdk.ConfigureProviderResponse) {
@@ -86,6 +200,46 @@ func (p *provider) Configure(ctx context.Context, req tfsdk.ConfigureProviderReq
 		return
 	}
 
+	apikeyid := stringFromEnv(config.ApikeyId, "DEMISTO_API_KEY_ID")
+	authType := stringFromEnv(config.AuthType, "DEMISTO_AUTH_TYPE")
+	if authType == "" {
+		authType = authTypeStandard
+	}
+	if authType != authTypeStandard && authType != authTypeAdvanced {
+		resp.Diagnostics.AddError(
+			"Invalid authentication type",
+			"auth_type must be \""+authTypeStandard+"\" or \""+authTypeAdvanced+"\", got \""+authType+"\"",
+		)
+		return
+	}
+	if authType == authTypeAdvanced && apikeyid == "" {
+		resp.Diagnostics.AddError(
+			"Unable to find API key ID",
+			"Advanced API keys require api_key_id",
+		)
+		return
+	}
+	product := stringFromEnv(config.Product, "DEMISTO_PRODUCT")
+	if product == "" {
+		product = productXSOAR6
+	}
+	if product != productXSOAR6 && product != productXSOAR8 && product != productXSIAM {
+		resp.Diagnostics.AddError(
+			"Invalid product",
+			"product must be \""+productXSOAR6+"\", \""+productXSOAR8+"\" or \""+productXSIAM+"\", got \""+product+"\"",
+		)
+		return
+	}
+	if product == productXSOAR6 && apikeyid != "" {
+		resp.Diagnostics.AddWarning(
+			"API key ID set for XSOAR 6",
+			"api_key_id is set but product is \""+productXSOAR6+"\", so the API is not reached under "+xsoar8PathPrefix+". Set product to \""+productXSOAR8+"\" or \""+productXSIAM+"\" for XSOAR 8 and XSIAM tenants.",
+		)
+	}
+	config.ApikeyId = types.String{Value: apikeyid}
+	config.AuthType = types.String{Value: authType}
+	config.Product = types.String{Value: product}
+
 	// User must specify a host
 	var mainhost string
 	if config.MainHost.Unknown {
@@ -125,23 +279,88 @@ func (p *provider) Configure(ctx context.Context, req tfsdk.ConfigureProviderReq
 	}
 	insecure = config.Insecure.Value
 
+	tlsConfig, err := providerTLSConfig(config, insecure)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid TLS configuration",
+			"Could not configure TLS: "+err.Error(),
+		)
+		return
+	}
+
+	proxy, err := providerProxy(config)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid proxy configuration",
+			"Could not configure proxy: "+err.Error(),
+		)
+		return
+	}
+
 	// Create a new xsoar client and set it to the provider client
 	openapiConfig := openapi.NewConfiguration()
-	openapiConfig.Servers[0].URL = mainhost
-	openapiConfig.AddDefaultHeader("Authorization", apikey)
+	openapiConfig.Servers[0].URL = apiBaseUrl(mainhost, product)
 	openapiConfig.AddDefaultHeader("Accept", "application/json,*/*")
-	if insecure {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	for name, value := range config.ExtraHeaders.Elems {
+		if header, ok := value.(types.String); ok && !header.Null {
+			openapiConfig.AddDefaultHeader(name, header.Value)
 		}
-		client := &http.Client{Transport: tr}
-		openapiConfig.HTTPClient = client
 	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = proxy
+	if tlsConfig != nil {
+		tr.TLSClientConfig = tlsConfig
+	}
+	retry := providerRetryConfig(config)
+	if retry.MinWait > retry.MaxWait {
+		resp.Diagnostics.AddError(
+			"Invalid retry configuration",
+			"retry.min_wait cannot be greater than retry.max_wait",
+		)
+		return
+	}
+	if config.MaxConcurrentRequests.Value < 0 || config.RequestsPerSecond.Value < 0 {
+		resp.Diagnostics.AddError(
+			"Invalid rate limit configuration",
+			"max_concurrent_requests and requests_per_second cannot be negative",
+		)
+		return
+	}
+	// every attempt of a retried request is signed and goes through the limiter, which is shared by all resources and
+	// data sources
+	auth := &apiAuth{apiKey: apikey, apiKeyID: apikeyid, authType: authType}
+	var limited http.RoundTripper = &authTransport{next: &loggingTransport{next: tr}, auth: auth}
+	if limiter := newRequestLimiter(config.MaxConcurrentRequests.Value, config.RequestsPerSecond.Value); limiter != nil {
+		limited = &limitTransport{next: limited, limiter: limiter}
+	}
+	// request_timeout limits each attempt, so it does not cut the retries short
+	transport := &retryTransport{next: limited, config: retry}
+	if !config.RequestTimeout.Null && config.RequestTimeout.Value > 0 {
+		transport.timeout = time.Duration(config.RequestTimeout.Value) * time.Second
+	}
+	httpClient := &http.Client{Transport: transport}
+	openapiConfig.HTTPClient = httpClient
 	c := openapi.NewAPIClient(openapiConfig)
 
+	// the version and mode of the server gate features that it does not support. Failing to detect them is not an
+	// error, e.g. the API key may only be created by an xsoar_main_host resource of the same configuration.
+	server, err := detectServer(ctx, c, product != productXSOAR6)
+	if err != nil {
+		tflog.Warn(ctx, "Could not detect the version of the main server", map[string]interface{}{"error": err.Error()})
+	} else {
+		fields := map[string]interface{}{"version": server.Version}
+		if server.MultiTenant != nil {
+			fields["multi_tenant"] = *server.MultiTenant
+		}
+		tflog.Info(ctx, "Detected main server", fields)
+	}
+
 	p.client = c
 	p.configured = true
 	p.data = &config
+	p.auth = auth
+	p.server = server
+	p.api = client.New(c)
 }
 
 // GetResources - Defines provider resources
@@ -153,6 +372,10 @@ func (p *provider) GetResources(_ context.Context) (map[string]tfsdk.ResourceTyp
 		"xsoar_integration_instance": resourceIntegrationInstanceType{},
 		"xsoar_classifier":           resourceClassifierType{},
 		"xsoar_mapper":               resourceMapperType{},
+		"xsoar_engine":               resourceEngineType{},
+		"xsoar_engine_group":         resourceEngineGroupType{},
+		"xsoar_main_host":            resourceMainHostType{},
+		"xsoar_api_key":              resourceApiKeyType{},
 	}, nil
 }
 
@@ -167,5 +390,110 @@ func (p *provider) GetDataSources(_ context.Context) (map[string]tfsdk.DataSourc
 		"xsoar_integration_instance": dataSourceIntegrationInstanceType{},
 		"xsoar_classifier":           dataSourceClassifierType{},
 		"xsoar_mapper":               dataSourceMapperType{},
+		"xsoar_engines":              dataSourceEnginesType{},
+	}, nil
+}
+
+// stringFromEnv returns the configured value, or the value of the environment variable when it is not configured
+func stringFromEnv(value types.String, name string) string {
+	if value.Null || value.Unknown {
+		return os.Getenv(name)
+	}
+	return value.Value
+}
+
+// providerTLSConfig builds the TLS settings of the API client. It returns nil when nothing is configured, so the
+// default transport is used.
+func providerTLSConfig(config providerData, insecure bool) (*tls.Config, error) {
+	caCertFile := stringFromEnv(config.CACertFile, "DEMISTO_CA_CERT_FILE")
+	caCertPEM := stringFromEnv(config.CACertPEM, "DEMISTO_CA_CERT_PEM")
+	clientCert := stringFromEnv(config.ClientCert, "DEMISTO_CLIENT_CERT")
+	clientKey := stringFromEnv(config.ClientKey, "DEMISTO_CLIENT_KEY")
+	serverName := stringFromEnv(config.TLSServerName, "DEMISTO_TLS_SERVER_NAME")
+	if !insecure && caCertFile == "" && caCertPEM == "" && clientCert == "" && clientKey == "" && serverName == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecure,
+		ServerName:         serverName,
+	}
+
+	if caCertFile != "" || caCertPEM != "" {
+		// the CAs are added to the system pool, so public certificates are still trusted
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if caCertFile != "" {
+			pem, err := os.ReadFile(caCertFile)
+			if err != nil {
+				return nil, fmt.Errorf("could not read ca_cert_file: %s", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("ca_cert_file %s contains no PEM encoded certificates", caCertFile)
+			}
+		}
+		if caCertPEM != "" && !pool.AppendCertsFromPEM([]byte(caCertPEM)) {
+			return nil, fmt.Errorf("ca_cert_pem contains no PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if clientCert != "" || clientKey != "" {
+		if clientCert == "" || clientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// providerProxy returns the proxy function of the API client. Without proxy_url the proxy is taken from the
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+func providerProxy(config providerData) (func(*http.Request) (*url.URL, error), error) {
+	if config.ProxyUrl.Null || len(config.ProxyUrl.Value) == 0 {
+		return http.ProxyFromEnvironment, nil
+	}
+	if _, err := url.Parse(config.ProxyUrl.Value); err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %s", err)
+	}
+	var noProxy []string
+	for _, elem := range config.NoProxy.Elems {
+		if host, ok := elem.(types.String); ok && !host.Null {
+			noProxy = append(noProxy, host.Value)
+		}
+	}
+	proxyConfig := httpproxy.Config{
+		HTTPProxy:  config.ProxyUrl.Value,
+		HTTPSProxy: config.ProxyUrl.Value,
+		NoProxy:    strings.Join(noProxy, ","),
+	}
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
 	}, nil
 }
+
+// providerRetryConfig returns the retry settings, using the defaults for those not configured
+func providerRetryConfig(config providerData) retryConfig {
+	retry := defaultRetryConfig
+	if len(config.Retry) == 0 {
+		return retry
+	}
+	block := config.Retry[0]
+	if !block.MaxAttempts.Null && block.MaxAttempts.Value > 0 {
+		retry.MaxAttempts = int(block.MaxAttempts.Value)
+	}
+	if !block.MinWait.Null && block.MinWait.Value >= 0 {
+		retry.MinWait = time.Duration(block.MinWait.Value) * time.Second
+	}
+	if !block.MaxWait.Null && block.MaxWait.Value >= 0 {
+		retry.MaxWait = time.Duration(block.MaxWait.Value) * time.Second
+	}
+	return retry
+}
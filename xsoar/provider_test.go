@@ -4,7 +4,9 @@ import (
 	"crypto/tls"
 	"github.com/badarsebard/xsoar-sdk-go/openapi"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"testing"
 )
 
 var openapiClient *openapi.APIClient
@@ -23,3 +25,13 @@ func init() {
 	openapiConfig.HTTPClient = client
 	openapiClient = openapi.NewAPIClient(openapiConfig)
 }
+
+// testServerClient returns a client for a test server that answers every request with handler
+func testServerClient(t *testing.T, handler http.HandlerFunc) *openapi.APIClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	cfg := openapi.NewConfiguration()
+	cfg.Servers[0].URL = server.URL
+	cfg.HTTPClient = server.Client()
+	return openapi.NewAPIClient(cfg)
+}
@@ -9,11 +9,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"hash/crc64"
 	"io"
 	"log"
-	"math/rand"
 	"net/http"
+	"terraform-provider-xsoar/internal/client"
 	"time"
 )
 
@@ -94,13 +93,17 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
-		log.Printf("%+v\n", req.Plan)
+		return
+	}
+
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_account", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	// Generate API request body from plan
 	createAccountRequest := *openapi.NewCreateAccountRequest()
-	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+	haGroup, err := r.p.api.FindHAGroupByName(ctx, plan.HostGroupName.Value)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error listing HA groups",
@@ -108,31 +111,40 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		)
 		return
 	}
-	var hostGroupId = ""
-	for _, group := range haGroups {
-		if group["name"].(string) == plan.HostGroupName.Value {
-			hostGroupId = group["id"].(string)
-			break
-		}
+	if haGroup == nil {
+		resp.Diagnostics.AddError(
+			"Error creating account",
+			"Could not find host or HA group "+plan.HostGroupName.Value,
+		)
+		return
 	}
-	createAccountRequest.SetHostGroupId(hostGroupId)
+	createAccountRequest.SetHostGroupId(haGroup.Id)
 	createAccountRequest.SetName(plan.Name.Value)
-	if !plan.AccountRoles.Null && len(plan.AccountRoles.Elems) > 0 {
-		var accountRoles []string
-		plan.AccountRoles.ElementsAs(ctx, &accountRoles, true)
-		createAccountRequest.SetAccountRoles(accountRoles)
-	} else {
-		createAccountRequest.SetAccountRoles([]string{"Administrator"})
+	hasRoles := !plan.AccountRoles.Null && len(plan.AccountRoles.Elems) > 0
+	if hasRoles {
+		resp.Diagnostics.Append(r.p.requireServer("account_roles", accountCreateMinVersion, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	// older servers reject both fields, their accounts get the default role and are synced on the server's schedule
+	if r.p.serverAtLeast(accountCreateMinVersion) {
+		if hasRoles {
+			var accountRoles []string
+			plan.AccountRoles.ElementsAs(ctx, &accountRoles, true)
+			createAccountRequest.SetAccountRoles(accountRoles)
+		} else {
+			createAccountRequest.SetAccountRoles([]string{"Administrator"})
+		}
+		createAccountRequest.SetSyncOnCreation(true)
 	}
 	if !plan.PropagationLabels.Null && len(plan.PropagationLabels.Elems) > 0 {
 		var propagationLabels []string
 		plan.PropagationLabels.ElementsAs(ctx, propagationLabels, true)
 		createAccountRequest.SetPropagationLabels(propagationLabels)
 	}
-	createAccountRequest.SetSyncOnCreation(true)
 
 	// Create new account
-	var accounts []map[string]interface{}
 	timeout := time.Duration(1800) * time.Second
 	if !plan.Timeout.Null && plan.Timeout.Value > 0 {
 		timeout = time.Duration(plan.Timeout.Value) * time.Second
@@ -140,36 +152,22 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 	err = resource.RetryContext(ctx, timeout, func() *resource.RetryError {
 		var httpResponse *http.Response
 		var body []byte
-		// initial random delay
-		crcTable := crc64.MakeTable(crc64.ISO)
-		seedInt := int64(crc64.Checksum([]byte(plan.Name.Value), crcTable))
-		log.Printf("generated seed: %d\n", seedInt)
-		randSource := rand.NewSource(seedInt)
-		nrand := rand.New(randSource)
-		randomTimeToWait := nrand.Intn(90) + 1
-		log.Printf("sleeping for %d seconds\n", randomTimeToWait)
-		time.Sleep(time.Duration(randomTimeToWait) * time.Second)
 		// wait until no other accounts are being created
-		accounts, httpResponse, err = r.p.client.DefaultApi.ListAccounts(ctx).Execute()
-		if httpResponse != nil {
-			body, _ = io.ReadAll(httpResponse.Body)
-			log.Printf("%s : %s\n", httpResponse.Status, body)
-		}
+		accounts, err := r.p.api.ListAccounts(ctx)
 		if err != nil {
-			return resource.RetryableError(fmt.Errorf("error message: %s, http response: %s", err, body))
+			return resource.RetryableError(fmt.Errorf("error listing accounts: %s", err))
 		}
 		var accountsBeingCreated int64 = 0
 		var concurrencyLimit int64 = 1
 		for _, account := range accounts {
-			if account["status"].(string) == "" {
+			if !account.Ready() {
 				accountsBeingCreated++
 			}
 			if !plan.Concurrency.Null {
 				concurrencyLimit = plan.Concurrency.Value
 			}
 			if accountsBeingCreated >= concurrencyLimit {
-				time.Sleep(60 * time.Second)
-				return resource.RetryableError(fmt.Errorf("waiting for account %s to finish creation", account["name"].(string)))
+				return resource.RetryableError(fmt.Errorf("waiting for account %s to finish creation", account.Name))
 			}
 		}
 		// Create account
@@ -178,13 +176,11 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		_, httpResponse, err = r.p.client.DefaultApi.CreateAccount(ctx).CreateAccountRequest(createAccountRequest).Execute()
 		if httpResponse != nil {
 			body, _ = io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("%s : %s - %s\n", payload, httpResponse.Status, body)
 		}
+		// transient failures are retried by the client, anything else will not go away by trying again
 		if err != nil {
 			log.Println(err.Error())
-			time.Sleep(60 * time.Second)
-			return resource.RetryableError(fmt.Errorf("error message: %s, http response: %s", err, body))
+			return resource.NonRetryableError(fmt.Errorf("error message: %s, http response: %s", err, body))
 		}
 
 		return nil
@@ -197,89 +193,34 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		return
 	}
 
-	var account map[string]interface{}
-	accName := "acc_" + plan.Name.Value
+	var account *client.Account
 	// Verify account created successfully
 	err = resource.RetryContext(ctx, timeout, func() *resource.RetryError {
-		account, _, err = r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
+		account, err = r.p.api.FindAccount(ctx, plan.Name.Value)
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error getting account",
-				"Could not read account "+accName+": "+err.Error(),
-			)
+			return resource.NonRetryableError(err)
 		}
-		if account["status"].(string) == "" {
-			time.Sleep(60 * time.Second)
-			return resource.RetryableError(fmt.Errorf("waiting for account %s to finish creation", account["name"].(string)))
+		if account == nil || !account.Ready() {
+			return resource.RetryableError(fmt.Errorf("waiting for account %s to finish creation", plan.Name.Value))
 		}
-
 		return nil
 	})
-
-	// Map response body to resource schema attribute
-	var result Account
-	var propagationLabels []attr.Value
-	if account["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, label := range account["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   label.(string),
-			})
-		}
-	}
-
-	var hostGroupName string
-	for _, group := range haGroups {
-		hgi, ok := account["hostGroupId"].(string)
-		if ok && group["id"].(string) == hgi {
-			hostGroupName = group["name"].(string)
-			break
-		}
-	}
-
-	var roles []attr.Value
-	if account["roles"] == nil {
-		roles = []attr.Value{}
-	} else {
-		rolesMap := account["roles"].(map[string]interface{})
-		rolesMapRoles := rolesMap["roles"].([]interface{})
-		var role string
-		var ok bool
-		for _, roleInterface := range rolesMapRoles {
-			role, ok = roleInterface.(string)
-			if ok {
-				roles = append(roles, types.String{
-					Unknown: false,
-					Null:    false,
-					Value:   role,
-				})
-			}
-		}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting account",
+			"Could not read account "+plan.Name.Value+": "+err.Error(),
+		)
+		return
 	}
 
-	result = Account{
-		Name:          types.String{Value: account["displayName"].(string)},
-		HostGroupName: types.String{Value: hostGroupName},
-		HostGroupId:   types.String{Value: hostGroupId},
-		PropagationLabels: types.Set{
-			Unknown:  false,
-			Null:     false,
-			Elems:    propagationLabels,
-			ElemType: types.StringType,
-		},
-		AccountRoles: types.Set{
-			Unknown:  false,
-			Null:     false,
-			Elems:    roles,
-			ElemType: types.StringType,
-		},
-		Id:          types.String{Value: account["id"].(string)},
-		Timeout:     plan.Timeout,
-		Concurrency: plan.Concurrency,
+	// Map response body to resource schema attribute
+	result, diags := readAccountModel(ctx, r.p.api, account)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	result.Timeout = plan.Timeout
+	result.Concurrency = plan.Concurrency
 
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, &result)
@@ -299,15 +240,12 @@ func (r resourceAccount) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 		return
 	}
 
-	// Get account from API and then update what is in state from what the API returns
-	accName := "acc_" + state.Name.Value
-
 	// Get account current value
-	account, _, err := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
+	account, err := r.p.api.FindAccount(ctx, state.Name.Value)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting account",
-			"Could not read account "+accName+": "+err.Error(),
+			"Could not read account "+state.Name.Value+": "+err.Error(),
 		)
 		return
 	}
@@ -316,84 +254,17 @@ func (r resourceAccount) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 		return
 	}
 
-	var propagationLabels []attr.Value
-	if account["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, prop := range account["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   prop.(string),
-			})
-		}
-	}
-
-	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing account details",
-			"Could not read account details"+err.Error(),
-		)
-		return
-	}
-	var roles []attr.Value
-	for _, detail := range details {
-		castDetail, ok1 := detail.(map[string]interface{})
-		accountName, ok2 := account["name"].(string)
-		if ok1 && ok2 && accountName == castDetail["name"].(string) {
-			roleObjects := castDetail["roles"].([]interface{})
-			for _, roleObject := range roleObjects {
-				roleName := roleObject.(map[string]interface{})["name"]
-				roles = append(roles, types.String{
-					Unknown: false,
-					Null:    false,
-					Value:   roleName.(string),
-				})
-			}
-		}
-	}
-	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
-		)
-		return
-	}
-	var hostGroupName = ""
-	for _, group := range haGroups {
-		hostGroupId, ok := account["hostGroupId"].(string)
-		if ok && group["id"].(string) == hostGroupId {
-			hostGroupName = group["name"].(string)
-			break
-		}
-	}
-
 	// Map response body to resource schema attribute
-	state = Account{
-		Name:          types.String{Value: account["displayName"].(string)},
-		HostGroupName: types.String{Value: hostGroupName},
-		HostGroupId:   types.String{Value: account["hostGroupId"].(string)},
-		PropagationLabels: types.Set{
-			Unknown:  false,
-			Null:     false,
-			Elems:    propagationLabels,
-			ElemType: types.StringType,
-		},
-		AccountRoles: types.Set{
-			Unknown:  false,
-			Null:     false,
-			Elems:    roles,
-			ElemType: types.StringType,
-		},
-		Id:          types.String{Value: account["id"].(string)},
-		Timeout:     state.Timeout,
-		Concurrency: state.Concurrency,
+	result, diags := readAccountModel(ctx, r.p.api, account)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	result.Timeout = state.Timeout
+	result.Concurrency = state.Concurrency
 
 	// Set state
-	diags = resp.State.Set(ctx, &state)
+	diags = resp.State.Set(ctx, &result)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -410,6 +281,11 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_account", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Get current state
 	var state Account
 	diags = req.State.Get(ctx, &state)
@@ -516,22 +392,22 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 	// Host
 	// todo: implement after updating sdk with account host migration capability
 	if plan.HostGroupName.Value != state.HostGroupName.Value {
-		haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+		haGroup, err := r.p.api.FindHAGroupByName(ctx, plan.HostGroupName.Value)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error listing HA groups",
-				"Could not read HA groups"+err.Error(),
+				"Could not read HA groups: "+err.Error(),
 			)
 			return
 		}
-		var targetHostGroupId = ""
-		for _, group := range haGroups {
-			if group["name"].(string) == plan.HostGroupName.Value {
-				targetHostGroupId = group["id"].(string)
-				break
-			}
+		if haGroup == nil {
+			resp.Diagnostics.AddError(
+				"Error updating account host",
+				"Could not find host or HA group "+plan.HostGroupName.Value,
+			)
+			return
 		}
-		_, _, err = r.p.client.DefaultApi.UpdateAccountHost(ctx, "acc_"+plan.Name.Value, targetHostGroupId).Execute()
+		_, _, err = r.p.client.DefaultApi.UpdateAccountHost(ctx, "acc_"+plan.Name.Value, haGroup.Id).Execute()
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error updating account host",
@@ -541,15 +417,12 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		}
 	}
 
-	// Get account from API and then update what is in state from what the API returns
-	accName := "acc_" + state.Name.Value
-
 	// Get account current value
-	account, _, err := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
+	account, err := r.p.api.FindAccount(ctx, state.Name.Value)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting account",
-			"Could not read account "+accName+": "+err.Error(),
+			"Could not read account "+state.Name.Value+": "+err.Error(),
 		)
 		return
 	}
@@ -558,79 +431,14 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		return
 	}
 
-	var propagationLabels []attr.Value
-	if account["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, prop := range account["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   prop.(string),
-			})
-		}
-	}
-
-	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing account details",
-			"Could not read account details"+err.Error(),
-		)
-		return
-	}
-	var roles []attr.Value
-	for _, detail := range details {
-		castDetail := detail.(map[string]interface{})
-		if account["name"].(string) == castDetail["name"].(string) {
-			roleObjects := castDetail["roles"].([]interface{})
-			for _, roleObject := range roleObjects {
-				roleName := roleObject.(map[string]interface{})["name"]
-				roles = append(roles, types.String{
-					Unknown: false,
-					Null:    false,
-					Value:   roleName.(string),
-				})
-			}
-		}
-	}
-	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
-		)
-		return
-	}
-	var hostGroupName = ""
-	for _, group := range haGroups {
-		hostGroupId, ok := account["hostGroupId"].(string)
-		if ok && group["id"].(string) == hostGroupId {
-			hostGroupName = group["name"].(string)
-			break
-		}
-	}
 	// Map response body to resource schema attribute
-	result := Account{
-		Name:          types.String{Value: account["displayName"].(string)},
-		HostGroupName: types.String{Value: hostGroupName},
-		HostGroupId:   types.String{Value: account["hostGroupId"].(string)},
-		PropagationLabels: types.Set{
-			Unknown:  false,
-			Null:     false,
-			Elems:    propagationLabels,
-			ElemType: types.StringType,
-		},
-		AccountRoles: types.Set{
-			Unknown:  false,
-			Null:     false,
-			Elems:    roles,
-			ElemType: types.StringType,
-		},
-		Id:          types.String{Value: account["id"].(string)},
-		Timeout:     plan.Timeout,
-		Concurrency: plan.Concurrency,
+	result, diags := readAccountModel(ctx, r.p.api, account)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	result.Timeout = plan.Timeout
+	result.Concurrency = plan.Concurrency
 
 	// Set state
 	diags = resp.State.Set(ctx, &result)
@@ -653,16 +461,14 @@ func (r resourceAccount) Delete(ctx context.Context, req tfsdk.DeleteResourceReq
 
 	err := resource.RetryContext(ctx, 300*time.Second, func() *resource.RetryError {
 		// Get account current value
-		account, _, _ := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
+		account, err := r.p.api.FindAccount(ctx, state.Name.Value)
+		if err != nil {
+			return resource.RetryableError(fmt.Errorf("error getting account: %s", err))
+		}
 		if account != nil {
-			_, httpResponse, err := r.p.client.DefaultApi.DeleteAccount(ctx, accName).Execute()
+			_, _, err := r.p.client.DefaultApi.DeleteAccount(ctx, accName).Execute()
 			if err != nil {
 				log.Println(err.Error())
-				if httpResponse != nil {
-					body, _ := io.ReadAll(httpResponse.Body)
-					payload, _ := io.ReadAll(httpResponse.Request.Body)
-					log.Printf("code: %d status: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body), string(payload))
-				}
 				return resource.RetryableError(fmt.Errorf("error deleting instance: %s", err))
 			}
 		}
@@ -679,95 +485,94 @@ func (r resourceAccount) Delete(ctx context.Context, req tfsdk.DeleteResourceReq
 }
 
 func (r resourceAccount) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
-	var diags diag.Diagnostics
-	accName := "acc_" + req.ID
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_account", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Get account current value
-	account, _, err := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
+	account, err := r.p.api.FindAccount(ctx, req.ID)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting account",
-			"Could not read account "+accName+": "+err.Error(),
+			"Could not read account "+req.ID+": "+err.Error(),
+		)
+		return
+	}
+	if account == nil {
+		resp.Diagnostics.AddError(
+			"Error importing account",
+			"Could not find account "+req.ID,
 		)
 		return
 	}
 
-	var propagationLabels []attr.Value
-	if account["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, prop := range account["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   prop.(string),
-			})
-		}
+	// Map response body to resource schema attribute
+	state, diags := readAccountModel(ctx, r.p.api, account)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Timeout = types.Int64{Value: 900}
+	state.Concurrency = types.Int64{Value: 1}
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+}
 
-	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
+// readAccountModel maps an account to the schema. The roles of the account and the name of its host group are read
+// from the main server.
+func readAccountModel(ctx context.Context, api *client.Client, account *client.Account) (Account, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	accountRoles, err := api.ListAccountRoles(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error listing account details",
-			"Could not read account details"+err.Error(),
+			"Could not read account details: "+err.Error(),
 		)
-		return
-	}
-	var roles []attr.Value
-	for _, detail := range details {
-		castDetail := detail.(map[string]interface{})
-		if account["name"].(string) == castDetail["name"].(string) {
-			roleObjects := castDetail["roles"].([]interface{})
-			for _, roleObject := range roleObjects {
-				roleName := roleObject.(map[string]interface{})["name"]
-				roles = append(roles, types.String{
-					Unknown: false,
-					Null:    false,
-					Value:   roleName.(string),
-				})
-			}
-		}
+		return Account{}, diags
 	}
-	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+	haGroups, err := api.ListHAGroups(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
+			"Could not read HA groups: "+err.Error(),
 		)
-		return
+		return Account{}, diags
+	}
+
+	propagationLabels := []attr.Value{}
+	for _, label := range account.PropagationLabels {
+		propagationLabels = append(propagationLabels, types.String{Value: label})
+	}
+	var roles []attr.Value
+	for _, role := range accountRoles[account.Name] {
+		roles = append(roles, types.String{Value: role})
 	}
 	var hostGroupName = ""
 	for _, group := range haGroups {
-		hostGroupId, ok := account["hostGroupId"].(string)
-		if ok && group["id"].(string) == hostGroupId {
-			hostGroupName = group["name"].(string)
+		if group.Id == account.HostGroupId {
+			hostGroupName = group.Name
 			break
 		}
 	}
-	// Map response body to resource schema attribute
-	var state = Account{
-		Name:          types.String{Value: account["displayName"].(string)},
+
+	return Account{
+		Name:          types.String{Value: account.DisplayName},
 		HostGroupName: types.String{Value: hostGroupName},
+		HostGroupId:   types.String{Value: account.HostGroupId},
 		PropagationLabels: types.Set{
-			Unknown:  false,
-			Null:     false,
 			Elems:    propagationLabels,
 			ElemType: types.StringType,
 		},
 		AccountRoles: types.Set{
-			Unknown:  false,
-			Null:     false,
 			Elems:    roles,
 			ElemType: types.StringType,
 		},
-		Id:          types.String{Value: account["id"].(string)},
-		Timeout:     types.Int64{Value: 900},
-		Concurrency: types.Int64{Value: 1},
-	}
-
-	// Set state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+		Id: types.String{Value: account.Id},
+	}, diags
 }
@@ -0,0 +1,292 @@
+package xsoar
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type resourceApiKeyType struct{}
+
+// GetSchema Resource schema
+func (r resourceApiKeyType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:          types.StringType,
+				Required:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"id": {
+				Type:          types.StringType,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.UseStateForUnknown()),
+			},
+			"value": {
+				Type:          types.StringType,
+				Optional:      true,
+				Computed:      true,
+				Sensitive:     true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace(), tfsdk.UseStateForUnknown()),
+			},
+			"account": {
+				Type:          types.StringType,
+				Optional:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+		},
+	}, nil
+}
+
+// NewResource instance
+func (r resourceApiKeyType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceApiKey{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+type resourceApiKey struct {
+	p provider
+}
+
+// Create a new resource
+func (r resourceApiKey) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource. This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
+		)
+		return
+	}
+
+	// Retrieve values from plan
+	var plan ApiKey
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_api_key", apiKeyMinVersion, false)...)
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(plan.Account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// keys are looked up by name, so a second key with the same name would be taken for this one
+	existing, err := r.p.api.FindAPIKey(ctx, plan.Account.Value, plan.Name.Value)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting API key",
+			"Could not get API key "+plan.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+	if existing != nil {
+		resp.Diagnostics.AddError(
+			"Error creating API key",
+			"An API key named "+plan.Name.Value+" already exists, import it or choose another name",
+		)
+		return
+	}
+
+	value := plan.Value.Value
+	if plan.Value.Unknown || plan.Value.Null {
+		var err error
+		value, err = generateApiKey()
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error generating API key",
+				"Could not generate API key: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	// Create new API key
+	err = r.p.api.CreateAPIKey(ctx, plan.Account.Value, plan.Name.Value, value)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating API key",
+			"Could not create API key "+plan.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+
+	// the response does not reliably contain the new key, so look it up by name
+	key, err := r.p.api.FindAPIKey(ctx, plan.Account.Value, plan.Name.Value)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting API key",
+			"Could not get API key "+plan.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+	if key == nil {
+		resp.Diagnostics.AddError(
+			"Error getting API key",
+			"API key "+plan.Name.Value+" was not found after it was created",
+		)
+		return
+	}
+
+	result := plan
+	result.Id = types.String{Value: key.Id}
+	result.Value = types.String{Value: value}
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourceApiKey) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	// Get current state
+	var state ApiKey
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key, err := r.p.api.GetAPIKey(ctx, state.Account.Value, state.Id.Value)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting API key",
+			"Could not get API key "+state.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+	// the key was revoked outside of Terraform
+	if key == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	result := state
+	if len(key.Name) > 0 {
+		result.Name = types.String{Value: key.Name}
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource
+func (r resourceApiKey) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	// Every attribute forces a new key, so there is nothing to update on the server
+	var plan ApiKey
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourceApiKey) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	var state ApiKey
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Revoke API key
+	httpResponse, err := r.p.api.RevokeAPIKey(ctx, state.Account.Value, state.Id.Value)
+	if err != nil && (httpResponse == nil || httpResponse.StatusCode != http.StatusNotFound) {
+		resp.Diagnostics.AddError(
+			"Error deleting API key",
+			"Could not revoke API key "+state.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState imports an API key by name, or by account.name for a key of an account. The value of the key cannot
+// be read back and is left empty.
+func (r resourceApiKey) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	account := types.String{Null: true}
+	name := req.ID
+	if accname := strings.SplitN(req.ID, ".", 2); len(accname) == 2 {
+		account = types.String{Value: accname[0]}
+		name = accname[1]
+	}
+
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_api_key", apiKeyMinVersion, false)...)
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key, err := r.p.api.FindAPIKey(ctx, account.Value, name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error importing API key",
+			"Could not import API key: "+err.Error(),
+		)
+		return
+	}
+	if key == nil {
+		resp.Diagnostics.AddError(
+			"Error importing API key",
+			"Could not find API key "+req.ID,
+		)
+		return
+	}
+
+	result := ApiKey{
+		Name:    types.String{Value: name},
+		Id:      types.String{Value: key.Id},
+		Value:   types.String{Null: true},
+		Account: account,
+	}
+
+	// Generate resource state struct
+	diags := resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// accountPathPrefix returns the path prefix of the API endpoints of an account, or an empty prefix for the main account
+func accountPathPrefix(account types.String) string {
+	if account.Null || len(account.Value) == 0 {
+		return ""
+	}
+	return "/acc_" + url.PathEscape(account.Value)
+}
+
+// generateApiKey returns a random key in the format of the keys generated by the server
+func generateApiKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
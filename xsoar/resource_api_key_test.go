@@ -0,0 +1,180 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"net/http"
+	"strings"
+	"terraform-provider-xsoar/internal/client"
+	"testing"
+)
+
+func TestAccApiKey_basic(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccApiKeyResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6(New()())(), nil
+			},
+		},
+		CheckDestroy: testAccCheckApiKeyResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccApiKeyResourceBasic(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckApiKeyResourceExists(rName),
+					resource.TestCheckResourceAttrSet("xsoar_api_key."+rName, "value"),
+				),
+			},
+			{
+				ResourceName:            "xsoar_api_key." + rName,
+				ImportStateId:           rName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"value"},
+			},
+		},
+	})
+}
+
+func TestApiKeyImportState(t *testing.T) {
+	tests := map[string]struct {
+		id      string
+		path    string
+		name    string
+		account types.String
+	}{
+		"main account": {
+			id:      "key1",
+			path:    "/apikeys",
+			name:    "key1",
+			account: types.String{Null: true},
+		},
+		"account": {
+			id:      "tenant1.key1",
+			path:    "/acc_tenant1/apikeys",
+			name:    "key1",
+			account: types.String{Value: "tenant1"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := testServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != test.path {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`[{"id":"k1","name":"other"},{"id":"k2","name":"key1"}]`))
+			})
+			r := resourceApiKey{p: provider{configured: true, api: client.New(api)}}
+
+			ctx := context.Background()
+			schema, _ := resourceApiKeyType{}.GetSchema(ctx)
+			resp := &tfsdk.ImportResourceStateResponse{State: tfsdk.State{Schema: schema}}
+			r.ImportState(ctx, tfsdk.ImportResourceStateRequest{ID: test.id}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			var state ApiKey
+			resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if state.Id.Value != "k2" || state.Name.Value != test.name || !state.Account.Equal(test.account) {
+				t.Fatalf("unexpected API key %+v", state)
+			}
+			// the value cannot be read back
+			if !state.Value.Null {
+				t.Fatal("value should be null after import")
+			}
+		})
+	}
+}
+
+func TestApiKeyCreateDuplicateName(t *testing.T) {
+	api := testServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/apikeys" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"k1","name":"key1"}]`))
+	})
+	r := resourceApiKey{p: provider{configured: true, api: client.New(api)}}
+	ctx := context.Background()
+	schema, _ := resourceApiKeyType{}.GetSchema(ctx)
+	plan := tfsdk.Plan{Schema: schema}
+	diags := plan.Set(ctx, ApiKey{
+		Name:    types.String{Value: "key1"},
+		Id:      types.String{Unknown: true},
+		Value:   types.String{Unknown: true},
+		Account: types.String{Null: true},
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	resp := &tfsdk.CreateResourceResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, tfsdk.CreateResourceRequest{Plan: plan}, resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error for an existing key")
+	}
+	if detail := resp.Diagnostics[0].Detail(); !strings.Contains(detail, "already exists") {
+		t.Fatalf("unexpected error: %s", detail)
+	}
+}
+
+func testAccApiKeyResourcePreCheck(t *testing.T) {}
+
+func testAccCheckApiKeyResourceExists(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources["xsoar_api_key."+r]
+		if !ok {
+			return fmt.Errorf("not found: %s in %s", r, state.RootModule().Resources)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		key, err := client.New(openapiClient).FindAPIKey(context.Background(), "", r)
+		if err != nil {
+			return fmt.Errorf("Error getting API key: " + err.Error())
+		}
+		if key == nil {
+			return fmt.Errorf("API key " + r + " was not found")
+		}
+		if rsid := key.Id; rsid != rs.Primary.ID {
+			return fmt.Errorf("API key ID created (" + rsid + ") did not match state (" + rs.Primary.ID + ")")
+		}
+		return nil
+	}
+}
+
+func testAccCheckApiKeyResourceDestroy(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		key, err := client.New(openapiClient).FindAPIKey(context.Background(), "", r)
+		if err != nil {
+			return fmt.Errorf("Error getting API key: " + err.Error())
+		}
+		if key != nil {
+			return fmt.Errorf("found API key when none was expected")
+		}
+		return nil
+	}
+}
+
+func testAccApiKeyResourceBasic(name string) string {
+	c := `
+resource "xsoar_api_key" "{name}" {
+  name = "{name}"
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
@@ -3,15 +3,12 @@ package xsoar
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/badarsebard/xsoar-sdk-go/openapi"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
 	"log"
-	"net/http"
 	"strings"
 )
 
@@ -89,6 +86,12 @@ func (r resourceClassifier) Create(ctx context.Context, req tfsdk.CreateResource
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_classifier", classifierMinVersion, false)...)
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(plan.Account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Create
 	classifierRequest := *openapi.NewCreateUpdateClassifierRequest()
 	classifierRequest.SetType("classification")
@@ -127,16 +130,10 @@ func (r resourceClassifier) Create(ctx context.Context, req tfsdk.CreateResource
 		classifierRequest.SetPropagationLabels(props)
 	}
 	var classifier openapi.InstanceClassifier
-	var httpResponse *http.Response
 	if plan.Account.Null || len(plan.Account.Value) == 0 {
-		classifier, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(classifierRequest).Execute()
+		classifier, _, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(classifierRequest).Execute()
 	} else {
-		classifier, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(classifierRequest).Execute()
-	}
-	if httpResponse != nil {
-		getBody, _ := httpResponse.Request.GetBody()
-		b, _ := io.ReadAll(getBody)
-		log.Println(string(b))
+		classifier, _, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(classifierRequest).Execute()
 	}
 	if err != nil {
 		log.Println(err.Error())
@@ -219,12 +216,11 @@ func (r resourceClassifier) Read(ctx context.Context, req tfsdk.ReadResourceRequ
 
 	// Get resource from API
 	var classifier openapi.InstanceClassifier
-	var httpResponse *http.Response
 	var err error
 	if state.Account.Null || len(state.Account.Value) == 0 {
-		classifier, httpResponse, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(state.Name.Value).Execute()
+		classifier, _, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(state.Name.Value).Execute()
 	} else {
-		classifier, httpResponse, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+state.Account.Value).SetIdentifier(state.Name.Value).Execute()
+		classifier, _, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+state.Account.Value).SetIdentifier(state.Name.Value).Execute()
 	}
 	if err != nil {
 		// determine if the error is a not found error or not
@@ -235,11 +231,6 @@ func (r resourceClassifier) Read(ctx context.Context, req tfsdk.ReadResourceRequ
 			return
 		}
 		log.Println(err.Error())
-		if httpResponse != nil {
-			getBody, _ := httpResponse.Request.GetBody()
-			b, _ := io.ReadAll(getBody)
-			log.Println(string(b))
-		}
 		resp.Diagnostics.AddError(
 			"Error getting classifier",
 			"Could not get classifier: "+err.Error(),
@@ -317,6 +308,12 @@ func (r resourceClassifier) Update(ctx context.Context, req tfsdk.UpdateResource
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_classifier", classifierMinVersion, false)...)
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(plan.Account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Get current state
 	var state Classifier
 	diags = req.State.Get(ctx, &state)
@@ -364,19 +361,13 @@ func (r resourceClassifier) Update(ctx context.Context, req tfsdk.UpdateResource
 		classifierRequest.SetPropagationLabels(props)
 	}
 	var classifier openapi.InstanceClassifier
-	var httpResponse *http.Response
 	if plan.Account.Null || len(plan.Account.Value) == 0 {
-		classifier, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(classifierRequest).Execute()
+		classifier, _, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(classifierRequest).Execute()
 	} else {
-		classifier, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(classifierRequest).Execute()
+		classifier, _, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(classifierRequest).Execute()
 	}
 	if err != nil {
 		log.Println(err.Error())
-		if httpResponse != nil {
-			getBody, _ := httpResponse.Request.GetBody()
-			b, _ := io.ReadAll(getBody)
-			log.Println(string(b))
-		}
 		resp.Diagnostics.AddError(
 			"Error updating classifier",
 			"Could not update classifier: "+err.Error(),
@@ -455,20 +446,14 @@ func (r resourceClassifier) Delete(ctx context.Context, req tfsdk.DeleteResource
 	}
 
 	// Delete
-	var httpResponse *http.Response
 	var err error
 	if state.Account.Null || len(state.Account.Value) == 0 {
-		httpResponse, err = r.p.client.DefaultApi.DeleteClassifier(ctx, state.Id.Value).Execute()
+		_, err = r.p.client.DefaultApi.DeleteClassifier(ctx, state.Id.Value).Execute()
 	} else {
-		httpResponse, err = r.p.client.DefaultApi.DeleteClassifierAccount(ctx, state.Id.Value, "acc_"+state.Account.Value).Execute()
+		_, err = r.p.client.DefaultApi.DeleteClassifierAccount(ctx, state.Id.Value, "acc_"+state.Account.Value).Execute()
 	}
 	if err != nil {
 		log.Println(err.Error())
-		if httpResponse != nil {
-			getBody := httpResponse.Body
-			b, _ := io.ReadAll(getBody)
-			fmt.Println(string(b))
-		}
 		resp.Diagnostics.AddError(
 			"Error deleting mapper",
 			"Could not delete mapper: "+err.Error(),
@@ -481,6 +466,11 @@ func (r resourceClassifier) Delete(ctx context.Context, req tfsdk.DeleteResource
 }
 
 func (r resourceClassifier) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_classifier", classifierMinVersion, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var diags diag.Diagnostics
 	accname := strings.Split(req.ID, ".")
 	var acc, name string
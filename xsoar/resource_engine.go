@@ -0,0 +1,470 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"net/url"
+	"os"
+	"time"
+)
+
+// engineInstallerPath is where the engine installer is placed on the engine machine
+const engineInstallerPath = "/tmp/d1_installer.sh"
+
+type resourceEngineType struct{}
+
+// GetSchema Resource schema
+func (r resourceEngineType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:          types.StringType,
+				Required:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"id": {
+				Type:          types.StringType,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.UseStateForUnknown()),
+			},
+			"status": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"server_url": {
+				Type:          types.StringType,
+				Required:      true,
+				PlanModifiers: append(planModifiers, requiresReplaceUnlessImported()),
+			},
+			"ssh_user": {
+				Type:     types.StringType,
+				Required: true,
+			},
+			"ssh_key": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_key_passphrase": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_certificate": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"ssh_password": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_use_agent": {
+				Type:     types.BoolType,
+				Optional: true,
+			},
+			"host_key": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"known_hosts_file": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"observed_host_key": {
+				Type:          types.StringType,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.UseStateForUnknown()),
+			},
+			"installation_timeout": {
+				Type:     types.Int64Type,
+				Optional: true,
+			},
+			"installer_transfer": {
+				Type:       types.StringType,
+				Optional:   true,
+				Validators: []tfsdk.AttributeValidator{stringOneOf("pull", "push")},
+			},
+			"destroy_mode": {
+				Type:       types.StringType,
+				Optional:   true,
+				Validators: []tfsdk.AttributeValidator{stringOneOf("purge", "deregister_only", "skip")},
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"bastion": bastionBlock(),
+		},
+	}, nil
+}
+
+// NewResource instance
+func (r resourceEngineType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceEngine{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+type resourceEngine struct {
+	p provider
+}
+
+// ValidateConfig checks the SSH credentials can be used before anything is applied
+func (r resourceEngine) ValidateConfig(ctx context.Context, req tfsdk.ValidateResourceConfigRequest, resp *tfsdk.ValidateResourceConfigResponse) {
+	var config Engine
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(validateSSHConfig(path.Empty(), config.SSHKey, config.SSHKeyPassphrase, config.SSHCertificate, config.SSHPassword, config.SSHUseAgent)...)
+	resp.Diagnostics.Append(validateBastions(config.Bastions)...)
+}
+
+// Create a new resource
+func (r resourceEngine) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource. This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
+		)
+		return
+	}
+
+	// Retrieve values from plan
+	var plan Engine
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_engine", engineMinVersion, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// 1) connect to the engine machine over ssh
+	conn, verifier, diags := connectSSH(ctx, plan.sshTarget(), "")
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer conn.Close()
+
+	// 2) create the engine on the main server, which builds its installer
+	engine, err := r.p.api.BuildEngine(ctx, plan.Name.Value)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating engine",
+			"Could not create engine "+plan.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+	engineId := engine.Id
+	// the engine is not saved to state if any of the following steps fail, so remove it from main again
+	defer func() {
+		if !resp.Diagnostics.HasError() {
+			return
+		}
+		if deleteErr := r.p.api.DeleteEngine(ctx, engineId); deleteErr != nil {
+			resp.Diagnostics.AddWarning(
+				"Error removing engine",
+				"Could not remove engine "+plan.Name.Value+" from the main server after the failed install, remove it manually: "+deleteErr.Error(),
+			)
+		}
+	}()
+
+	// 3) transfer installer to the engine machine
+	downloadPath := "/engines/download/" + url.PathEscape(engineId)
+	err = transferInstaller(ctx, r.p, conn, plan.InstallerTransfer.Value, downloadPath, engineInstallerPath, func(ctx context.Context) (*os.File, error) {
+		return downloadFile(ctx, r.p.client, downloadPath)
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error downloading installer",
+			"Could not download engine installer: "+err.Error(),
+		)
+		return
+	}
+
+	// 4) Execute installer
+	err = runSSHCommand(ctx, conn, "engine installer", "sudo "+engineInstallerPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error running installer",
+			"Could not run engine installer: "+err.Error(),
+		)
+		return
+	}
+
+	// Wait for the engine to connect to the main server
+	timeout := 300 * time.Second
+	if !plan.InstallationTimeout.Null {
+		timeout = time.Duration(plan.InstallationTimeout.Value) * time.Second
+	}
+	var status string
+	err = resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		record, getErr := r.p.api.GetEngine(ctx, engineId)
+		if getErr != nil {
+			return resource.RetryableError(getErr)
+		}
+		if record == nil {
+			return resource.RetryableError(fmt.Errorf("engine %s is not listed by the main server", engineId))
+		}
+		status = record.ConnectionStatus()
+		if status != "connected" {
+			return resource.RetryableError(fmt.Errorf("engine status is %s", status))
+		}
+		return nil
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error waiting for engine",
+			"Engine "+plan.Name.Value+" did not connect before timeout: "+err.Error(),
+		)
+		return
+	}
+
+	result := plan
+	result.Id = types.String{Value: engineId}
+	result.Status = types.String{Value: status}
+	result.ObservedHostKey = types.String{Value: verifier.Observed}
+	result.Bastions = conn.observedBastions(plan.Bastions)
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourceEngine) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	// Get current state
+	var state Engine
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	engine, err := r.p.api.GetEngine(ctx, state.Id.Value)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting engine",
+			"Could not get engine "+state.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+	// the engine was removed outside of Terraform
+	if engine == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	result := state
+	if len(engine.Name) > 0 {
+		result.Name = types.String{Value: engine.Name}
+	}
+	result.Status = types.String{Value: engine.ConnectionStatus()}
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource
+func (r resourceEngine) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	// Get plan values
+	var plan Engine
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get current state
+	var state Engine
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// An imported engine has its host key recorded on the first apply that sets server_url, as do bastions added since
+	// the last apply
+	observedHostKey, bastions, diags := recordHostKeys(ctx, plan.sshTarget(), state.ObservedHostKey.Value)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Bastions = bastions
+
+	// The engine itself cannot be changed, only the settings used to reach it
+	result := plan
+	result.Id = state.Id
+	result.Status = state.Status
+	result.ObservedHostKey = types.String{Null: true}
+	if observedHostKey != "" {
+		result.ObservedHostKey = types.String{Value: observedHostKey}
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourceEngine) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	var state Engine
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	destroyMode := state.DestroyMode.Value
+	if state.DestroyMode.Null || len(destroyMode) == 0 {
+		destroyMode = "purge"
+	}
+	if destroyMode == "skip" {
+		tflog.SubsystemInfo(withLogSubsystem(ctx, apiLogSubsystem), apiLogSubsystem, "destroy_mode is skip, leaving engine in place", map[string]interface{}{
+			"engine": state.Name.Value,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Uninstall from the engine machine, not needed if it is only removed from main
+	if destroyMode == "purge" {
+		conn, _, diags := connectSSH(ctx, state.sshTarget(), state.ObservedHostKey.Value)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		defer conn.Close()
+		err := runSSHCommand(ctx, conn, "engine uninstall",
+			"sudo systemctl disable --now d1 || true; sudo rm -rf /usr/local/demisto /etc/systemd/system/d1.service && sudo systemctl daemon-reload")
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error uninstalling engine",
+				"Could not uninstall engine: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	// Delete engine from main
+	engine, err := r.p.api.GetEngine(ctx, state.Id.Value)
+	if err == nil && engine == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	err = r.p.api.DeleteEngine(ctx, state.Id.Value)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting engine",
+			"Could not delete engine "+state.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState imports an engine by name. The SSH settings are not known to the main server and are added to the state
+// on the next apply.
+func (r resourceEngine) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_engine", engineMinVersion, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	engine, err := r.p.api.FindEngine(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error listing engines",
+			"Could not list engines: "+err.Error(),
+		)
+		return
+	}
+	if engine == nil {
+		resp.Diagnostics.AddError(
+			"Error importing engine",
+			"Could not find engine "+req.ID,
+		)
+		return
+	}
+
+	result := Engine{
+		Name:                types.String{Value: req.ID},
+		Id:                  types.String{Value: engine.Id},
+		Status:              types.String{Value: engine.ConnectionStatus()},
+		ServerUrl:           types.String{Null: true},
+		SSHUser:             types.String{Null: true},
+		SSHKey:              types.String{Null: true},
+		SSHKeyPassphrase:    types.String{Null: true},
+		SSHCertificate:      types.String{Null: true},
+		SSHPassword:         types.String{Null: true},
+		SSHUseAgent:         types.Bool{Null: true},
+		HostKey:             types.String{Null: true},
+		KnownHostsFile:      types.String{Null: true},
+		ObservedHostKey:     types.String{Null: true},
+		InstallationTimeout: types.Int64{Null: true},
+		InstallerTransfer:   types.String{Null: true},
+		DestroyMode:         types.String{Null: true},
+		Bastions:            []Bastion{},
+	}
+
+	// Set state
+	diags := resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// sshTarget returns the SSH connection settings of the engine machine
+func (e Engine) sshTarget() sshTarget {
+	return sshTarget{
+		Address:        e.ServerUrl.Value,
+		User:           e.SSHUser.Value,
+		Key:            e.SSHKey.Value,
+		Passphrase:     e.SSHKeyPassphrase.Value,
+		Certificate:    e.SSHCertificate.Value,
+		Password:       e.SSHPassword.Value,
+		UseAgent:       e.SSHUseAgent.Value,
+		HostKey:        e.HostKey.Value,
+		KnownHostsFile: e.KnownHostsFile.Value,
+		Bastions:       e.Bastions,
+	}
+}
+
+// requiresReplaceUnlessImported replaces the resource when the attribute changes, except when it had no value before.
+// That is the case right after an import, where the setting is not known to the main server and is only taken from
+// the configuration on the next apply.
+func requiresReplaceUnlessImported() tfsdk.AttributePlanModifier {
+	return tfsdk.RequiresReplaceIf(
+		func(_ context.Context, state, _ attr.Value, _ path.Path) (bool, diag.Diagnostics) {
+			return !state.IsNull(), nil
+		},
+		"Changing the value requires replacement, unless it was not set because the resource was imported.",
+		"Changing the value requires replacement, unless it was not set because the resource was imported.",
+	)
+}
@@ -0,0 +1,251 @@
+package xsoar
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net/http"
+	"terraform-provider-xsoar/internal/client"
+)
+
+type resourceEngineGroupType struct{}
+
+// GetSchema Resource schema
+func (r resourceEngineGroupType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:     types.StringType,
+				Required: true,
+			},
+			"id": {
+				Type:          types.StringType,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.UseStateForUnknown()),
+			},
+			"engine_ids": {
+				Type:     types.SetType{ElemType: types.StringType},
+				Required: true,
+			},
+		},
+	}, nil
+}
+
+// NewResource instance
+func (r resourceEngineGroupType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceEngineGroup{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+type resourceEngineGroup struct {
+	p provider
+}
+
+// Create a new resource
+func (r resourceEngineGroup) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource. This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
+		)
+		return
+	}
+
+	// Retrieve values from plan
+	var plan EngineGroup
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_engine_group", engineMinVersion, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Create new engine group
+	group, err := r.p.api.SaveEngineGroup(ctx, "", plan.Name.Value, engineGroupIds(plan))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating engine group",
+			"Could not create engine group "+plan.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result := engineGroupFromRecord(group)
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourceEngineGroup) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	// Get current state
+	var state EngineGroup
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get engine group current value
+	group, httpResponse, err := r.p.api.GetEngineGroup(ctx, state.Id.Value)
+	if httpResponse != nil && httpResponse.StatusCode == http.StatusNotFound {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting engine group",
+			"Could not get engine group "+state.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result := engineGroupFromRecord(group)
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource
+func (r resourceEngineGroup) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	// Get plan values
+	var plan EngineGroup
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_engine_group", engineMinVersion, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get current state
+	var state EngineGroup
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Update engine group
+	group, err := r.p.api.SaveEngineGroup(ctx, state.Id.Value, plan.Name.Value, engineGroupIds(plan))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating engine group",
+			"Could not update engine group "+plan.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result := engineGroupFromRecord(group)
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourceEngineGroup) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	var state EngineGroup
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Delete engine group
+	httpResponse, err := r.p.api.DeleteEngineGroup(ctx, state.Id.Value)
+	if err != nil && (httpResponse == nil || httpResponse.StatusCode != http.StatusNotFound) {
+		resp.Diagnostics.AddError(
+			"Error deleting engine group",
+			"Could not delete engine group "+state.Name.Value+": "+err.Error(),
+		)
+		return
+	}
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState imports an engine group by name
+func (r resourceEngineGroup) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_engine_group", engineMinVersion, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	group, err := r.p.api.FindEngineGroup(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error listing engine groups",
+			"Could not read engine groups: "+err.Error(),
+		)
+		return
+	}
+	if group == nil {
+		resp.Diagnostics.AddError(
+			"Error importing engine group",
+			"Could not find engine group "+req.ID,
+		)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result := engineGroupFromRecord(group)
+
+	// Set state
+	diags := resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// engineGroupIds returns the ids of the engines of the group
+func engineGroupIds(group EngineGroup) []string {
+	var engineIds []string
+	for _, elem := range group.EngineIds.Elems {
+		engineIds = append(engineIds, elem.(types.String).Value)
+	}
+	return engineIds
+}
+
+// engineGroupFromRecord maps an engine group returned by the main server to the resource model
+func engineGroupFromRecord(group *client.EngineGroup) EngineGroup {
+	engineIds := []attr.Value{}
+	for _, engineId := range group.EngineIds {
+		engineIds = append(engineIds, types.String{Value: engineId})
+	}
+	return EngineGroup{
+		Name: types.String{Value: group.Name},
+		Id:   types.String{Value: group.Id},
+		EngineIds: types.Set{
+			Elems:    engineIds,
+			ElemType: types.StringType,
+		},
+	}
+}
@@ -0,0 +1,196 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"net/http"
+	"os"
+	"strings"
+	"terraform-provider-xsoar/internal/client"
+	"testing"
+)
+
+func TestAccEngine_basic(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccEngineResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6(New()())(), nil
+			},
+		},
+		CheckDestroy: testAccCheckEngineResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccEngineResourceBasic(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckEngineResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_engine."+rName, "status", "connected"),
+				),
+			},
+			{
+				ResourceName:      "xsoar_engine." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"server_url",
+					"ssh_user",
+					"ssh_key",
+					"observed_host_key",
+				},
+			},
+		},
+	})
+}
+
+func TestEngineImportState(t *testing.T) {
+	api := testServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/engines" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"engines":[{"id":"e1","name":"other"},{"id":"e2","name":"engine1","status":"Connected"}]}`))
+	})
+	r := resourceEngine{p: provider{configured: true, api: client.New(api)}}
+
+	ctx := context.Background()
+	schema, _ := resourceEngineType{}.GetSchema(ctx)
+	resp := &tfsdk.ImportResourceStateResponse{State: tfsdk.State{Schema: schema}}
+	r.ImportState(ctx, tfsdk.ImportResourceStateRequest{ID: "engine1"}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	var state Engine
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if state.Id.Value != "e2" || state.Status.Value != "connected" {
+		t.Fatalf("unexpected engine %s with status %s", state.Id.Value, state.Status.Value)
+	}
+	if !state.ServerUrl.Null || !state.SSHUser.Null {
+		t.Fatal("server_url and ssh_user are not known to the main server and should be null after import")
+	}
+
+	// the first apply after the import only adds the SSH settings to the state
+	plan := state
+	plan.ServerUrl = types.String{Value: "engine.example.com:22"}
+	plan.SSHUser = types.String{Value: "vagrant"}
+	if testEngineServerUrlRequiresReplace(t, state, plan) {
+		t.Error("setting server_url on an imported engine should not replace it")
+	}
+}
+
+func TestEngineServerUrlRequiresReplace(t *testing.T) {
+	state := Engine{
+		Name:      types.String{Value: "engine1"},
+		Id:        types.String{Value: "e1"},
+		ServerUrl: types.String{Value: "engine.example.com:22"},
+		SSHUser:   types.String{Value: "vagrant"},
+	}
+	moved := state
+	moved.ServerUrl = types.String{Value: "engine2.example.com:22"}
+	if !testEngineServerUrlRequiresReplace(t, state, moved) {
+		t.Error("changing server_url should replace the engine")
+	}
+	if testEngineServerUrlRequiresReplace(t, state, state) {
+		t.Error("an unchanged server_url should not replace the engine")
+	}
+}
+
+// testEngineServerUrlRequiresReplace runs the plan modifiers of server_url for a change from state to plan and returns
+// whether the engine would be replaced
+func testEngineServerUrlRequiresReplace(t *testing.T, state Engine, plan Engine) bool {
+	ctx := context.Background()
+	schema, _ := resourceEngineType{}.GetSchema(ctx)
+	req := tfsdk.ModifyAttributePlanRequest{
+		AttributePath:   path.Root("server_url"),
+		State:           tfsdk.State{Schema: schema},
+		Plan:            tfsdk.Plan{Schema: schema},
+		Config:          tfsdk.Config{Schema: schema},
+		AttributeState:  state.ServerUrl,
+		AttributePlan:   plan.ServerUrl,
+		AttributeConfig: plan.ServerUrl,
+	}
+	diags := req.State.Set(ctx, state)
+	diags.Append(req.Plan.Set(ctx, plan)...)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	req.Config.Raw = req.Plan.Raw
+	resp := &tfsdk.ModifyAttributePlanResponse{AttributePlan: req.AttributePlan}
+	for _, modifier := range schema.Attributes["server_url"].PlanModifiers {
+		modifier.Modify(ctx, req, resp)
+	}
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	return resp.RequiresReplace
+}
+
+func testAccEngineResourcePreCheck(t *testing.T) {}
+
+func testAccCheckEngineResourceExists(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources["xsoar_engine."+r]
+		if !ok {
+			return fmt.Errorf("not found: %s in %s", r, state.RootModule().Resources)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		engine, err := client.New(openapiClient).GetEngine(context.Background(), rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting engine: " + err.Error())
+		}
+		if engine == nil {
+			return fmt.Errorf("Engine " + rs.Primary.ID + " was not found")
+		}
+		return nil
+	}
+}
+
+func testAccCheckEngineResourceDestroy(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources["xsoar_engine."+r]
+		if !ok {
+			return fmt.Errorf("not found: %s in %s", r, state.RootModule().Resources)
+		}
+
+		engine, err := client.New(openapiClient).GetEngine(context.Background(), rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting engine: " + err.Error())
+		}
+		if engine != nil {
+			return fmt.Errorf("found engine when none was expected")
+		}
+		return nil
+	}
+}
+
+func testAccEngineResourceBasic(name string) string {
+	keyfile := os.Getenv("DEMISTO_ENGINE_KEYFILE")
+	host := os.Getenv("DEMISTO_ENGINE")
+	c := `
+resource "xsoar_engine" "{name}" {
+  name       = "{name}"
+  server_url = "{host}:22"
+  ssh_user   = "vagrant"
+  ssh_key    = file("{keyfile}")
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	c = strings.Replace(c, "{keyfile}", keyfile, -1)
+	c = strings.Replace(c, "{host}", host, -1)
+	return c
+}
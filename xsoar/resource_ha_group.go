@@ -7,8 +7,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
 	"log"
+	"net/http"
+	"terraform-provider-xsoar/internal/client"
 )
 
 type resourceHAGroupType struct{}
@@ -36,7 +37,41 @@ func (r resourceHAGroupType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Di
 				Required:      true,
 				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
 			},
-			// todo: add missing ES parameters
+			"elasticsearch_username": {
+				Type:     types.StringType,
+				Computed: true,
+				Optional: true,
+			},
+			"elasticsearch_password": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"elasticsearch_api_key": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"elasticsearch_shards": {
+				Type:     types.Int64Type,
+				Computed: true,
+				Optional: true,
+			},
+			"elasticsearch_replicas": {
+				Type:     types.Int64Type,
+				Computed: true,
+				Optional: true,
+			},
+			"elasticsearch_ca_cert": {
+				Type:     types.StringType,
+				Computed: true,
+				Optional: true,
+			},
+			"elasticsearch_verify_tls": {
+				Type:     types.BoolType,
+				Computed: true,
+				Optional: true,
+			},
 			"account_ids": {
 				Type:     types.SetType{ElemType: types.StringType},
 				Computed: true,
@@ -78,14 +113,14 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		return
 	}
 
-	// Generate API request body from plan
-	createHAGroupRequest := *openapi.NewCreateHAGroupRequest()
-	createHAGroupRequest.SetName(plan.Name.Value)
-	createHAGroupRequest.SetElasticIndexPrefix(plan.ElasticIndexPrefix.Value)
-	createHAGroupRequest.SetElasticsearchAddress(plan.ElasticsearchUrl.Value)
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_ha_group", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Create new HA group
-	haGroup, _, err := r.p.client.DefaultApi.CreateHAGroup(ctx).CreateHAGroupRequest(createHAGroupRequest).Execute()
+	var created openapi.CreateUpdateHAGroup
+	_, err := doJSON(ctx, r.p.client, http.MethodPost, "/ha-group/create", haGroupPayload("", plan), &created)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error creating HA group",
@@ -94,12 +129,7 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		return
 	}
 
-	haGroup, httpResponse, err := r.p.client.DefaultApi.GetHAGroup(ctx, haGroup.GetId()).Execute()
-	if httpResponse != nil {
-		body, _ := io.ReadAll(httpResponse.Body)
-		payload, _ := io.ReadAll(httpResponse.Request.Body)
-		log.Printf("code: %d status: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body), string(payload))
-	}
+	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, created.GetId()).Execute()
 	if err != nil {
 		log.Println(err.Error())
 		resp.Diagnostics.AddError(
@@ -108,12 +138,7 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		)
 		return
 	}
-	_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroup.GetId()).Execute()
-	if httpResponse != nil {
-		body, _ := io.ReadAll(httpResponse.Body)
-		payload, _ := io.ReadAll(httpResponse.Request.Body)
-		log.Printf("code: %d status: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body), string(payload))
-	}
+	_, _, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroup.GetId()).Execute()
 	if err != nil {
 		log.Println(err.Error())
 		resp.Diagnostics.AddError(
@@ -128,7 +153,7 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		accountIds = append(accountIds, types.String{Value: a})
 	}
 	var hostIds []attr.Value
-	for _, h := range haGroup.GetAccountIds() {
+	for _, h := range haGroup.GetHostIds() {
 		hostIds = append(hostIds, types.String{Value: h})
 	}
 
@@ -160,6 +185,16 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		result.HostIds.Elems = hostIds
 	}
 
+	record, err := r.p.api.GetHAGroup(ctx, haGroup.GetId())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting HA group",
+			"Could not get HA group "+haGroup.GetName()+": "+err.Error(),
+		)
+		return
+	}
+	setHAGroupElastic(record, plan, &result)
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -226,6 +261,16 @@ func (r resourceHAGroup) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 		result.HostIds.Elems = hostIds
 	}
 
+	record, err := r.p.api.GetHAGroup(ctx, haGroup.GetId())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting HA group",
+			"Could not get HA group "+haGroup.GetName()+": "+err.Error(),
+		)
+		return
+	}
+	setHAGroupElastic(record, state, &result)
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -244,6 +289,11 @@ func (r resourceHAGroup) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_ha_group", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Get current state
 	var state HAGroup
 	diags = req.State.Get(ctx, &state)
@@ -252,13 +302,9 @@ func (r resourceHAGroup) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		return
 	}
 
-	// Generate API request body from plan
-	updateHAGroupRequest := *openapi.NewCreateHAGroupRequest()
-	updateHAGroupRequest.SetId(state.Id.Value)
-	updateHAGroupRequest.SetName(plan.Name.Value)
-	updateHAGroupRequest.SetElasticsearchAddress(plan.ElasticsearchUrl.Value)
-	updateHAGroupRequest.SetElasticIndexPrefix(plan.ElasticIndexPrefix.Value)
-	haGroup, _, err := r.p.client.DefaultApi.CreateHAGroup(ctx).CreateHAGroupRequest(updateHAGroupRequest).Execute()
+	// Update the HA group, the create endpoint updates a group when its id is given
+	var haGroup openapi.CreateUpdateHAGroup
+	_, err := doJSON(ctx, r.p.client, http.MethodPost, "/ha-group/create", haGroupPayload(state.Id.Value, plan), &haGroup)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error updating HA group",
@@ -272,7 +318,7 @@ func (r resourceHAGroup) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		accountIds = append(accountIds, types.String{Value: a})
 	}
 	var hostIds []attr.Value
-	for _, h := range haGroup.GetAccountIds() {
+	for _, h := range haGroup.GetHostIds() {
 		hostIds = append(hostIds, types.String{Value: h})
 	}
 
@@ -304,6 +350,16 @@ func (r resourceHAGroup) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		result.HostIds.Elems = hostIds
 	}
 
+	record, err := r.p.api.GetHAGroup(ctx, haGroup.GetId())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting HA group",
+			"Could not get HA group "+haGroup.GetName()+": "+err.Error(),
+		)
+		return
+	}
+	setHAGroupElastic(record, plan, &result)
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -342,25 +398,30 @@ func (r resourceHAGroup) Delete(ctx context.Context, req tfsdk.DeleteResourceReq
 }
 
 func (r resourceHAGroup) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_ha_group", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var diags diag.Diagnostics
 	name := req.ID
 	// Get HA group current value
-	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+	group, err := r.p.api.FindHAGroupByName(ctx, name)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
+			"Could not read HA groups: "+err.Error(),
 		)
 		return
 	}
-	var id string
-	for _, group := range haGroups {
-		if group["name"].(string) == name {
-			id = group["id"].(string)
-			break
-		}
+	if group == nil {
+		resp.Diagnostics.AddError(
+			"Error importing HA group",
+			"Could not find HA group "+name,
+		)
+		return
 	}
-	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, id).Execute()
+	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, group.Id).Execute()
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting HA group",
@@ -374,7 +435,7 @@ func (r resourceHAGroup) ImportState(ctx context.Context, req tfsdk.ImportResour
 		accountIds = append(accountIds, types.String{Value: a})
 	}
 	var hostIds []attr.Value
-	for _, h := range haGroup.GetAccountIds() {
+	for _, h := range haGroup.GetHostIds() {
 		hostIds = append(hostIds, types.String{Value: h})
 	}
 
@@ -406,6 +467,24 @@ func (r resourceHAGroup) ImportState(ctx context.Context, req tfsdk.ImportResour
 		result.HostIds.Elems = hostIds
 	}
 
+	record, err := r.p.api.GetHAGroup(ctx, haGroup.GetId())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting HA group",
+			"Could not get HA group "+haGroup.GetName()+": "+err.Error(),
+		)
+		return
+	}
+	setHAGroupElastic(record, HAGroup{
+		ElasticsearchUsername:  types.String{Null: true},
+		ElasticsearchPassword:  types.String{Null: true},
+		ElasticsearchApiKey:    types.String{Null: true},
+		ElasticsearchShards:    types.Int64{Null: true},
+		ElasticsearchReplicas:  types.Int64{Null: true},
+		ElasticsearchCACert:    types.String{Null: true},
+		ElasticsearchVerifyTLS: types.Bool{Null: true},
+	}, &result)
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -413,3 +492,121 @@ func (r resourceHAGroup) ImportState(ctx context.Context, req tfsdk.ImportResour
 		return
 	}
 }
+
+// haGroupElastic holds the elasticsearch settings of an HA group that the API client does not model. Settings missing
+// from the API response are null.
+type haGroupElastic struct {
+	Username  types.String
+	Shards    types.Int64
+	Replicas  types.Int64
+	CACert    types.String
+	VerifyTLS types.Bool
+}
+
+// readHAGroupElastic reads the elasticsearch settings from an HA group record. Credentials are never returned by the
+// API, so they are not read.
+func readHAGroupElastic(group *client.HAGroup) haGroupElastic {
+	settings := haGroupElastic{
+		Username:  types.String{Null: true},
+		Shards:    types.Int64{Null: true},
+		Replicas:  types.Int64{Null: true},
+		CACert:    types.String{Null: true},
+		VerifyTLS: types.Bool{Null: true},
+	}
+	if len(group.ElasticsearchUsername) > 0 {
+		settings.Username = types.String{Value: group.ElasticsearchUsername}
+	}
+	if group.ElasticsearchShards != nil && *group.ElasticsearchShards > 0 {
+		settings.Shards = types.Int64{Value: *group.ElasticsearchShards}
+	}
+	if group.ElasticsearchReplicas != nil {
+		settings.Replicas = types.Int64{Value: *group.ElasticsearchReplicas}
+	}
+	if len(group.ElasticsearchCACert) > 0 {
+		settings.CACert = types.String{Value: group.ElasticsearchCACert}
+	}
+	if group.ElasticsearchInsecure != nil {
+		settings.VerifyTLS = types.Bool{Value: !*group.ElasticsearchInsecure}
+	}
+	return settings
+}
+
+// haGroupPayload builds the request to create an HA group, or to update it when id is set. The API client's request
+// only covers the address and index prefix, the other elasticsearch settings are sent alongside them.
+func haGroupPayload(id string, group HAGroup) map[string]interface{} {
+	payload := map[string]interface{}{
+		"name":                 group.Name.Value,
+		"elasticsearchAddress": group.ElasticsearchUrl.Value,
+		"elasticIndexPrefix":   group.ElasticIndexPrefix.Value,
+	}
+	if len(id) > 0 {
+		payload["id"] = id
+	}
+	if !group.ElasticsearchUsername.Null {
+		payload["elasticsearchUsername"] = group.ElasticsearchUsername.Value
+	}
+	if !group.ElasticsearchPassword.Null {
+		payload["elasticsearchPassword"] = group.ElasticsearchPassword.Value
+	}
+	if !group.ElasticsearchApiKey.Null {
+		payload["elasticsearchApiKey"] = group.ElasticsearchApiKey.Value
+	}
+	if !group.ElasticsearchShards.Null {
+		payload["elasticsearchShards"] = group.ElasticsearchShards.Value
+	}
+	if !group.ElasticsearchReplicas.Null {
+		payload["elasticsearchReplicas"] = group.ElasticsearchReplicas.Value
+	}
+	if !group.ElasticsearchCACert.Null {
+		payload["elasticsearchCACert"] = group.ElasticsearchCACert.Value
+	}
+	if !group.ElasticsearchVerifyTLS.Null {
+		payload["elasticsearchInsecure"] = !group.ElasticsearchVerifyTLS.Value
+	}
+	return payload
+}
+
+// setHAGroupElastic sets the elasticsearch settings of result from the API record, falling back to prior for settings
+// the API does not return
+func setHAGroupElastic(record *client.HAGroup, prior HAGroup, result *HAGroup) {
+	settings := readHAGroupElastic(record)
+	result.ElasticsearchPassword = prior.ElasticsearchPassword
+	result.ElasticsearchApiKey = prior.ElasticsearchApiKey
+	result.ElasticsearchUsername = settings.Username
+	if settings.Username.Null {
+		result.ElasticsearchUsername = prior.ElasticsearchUsername
+	}
+	result.ElasticsearchShards = settings.Shards
+	if settings.Shards.Null {
+		result.ElasticsearchShards = prior.ElasticsearchShards
+	}
+	result.ElasticsearchReplicas = settings.Replicas
+	if settings.Replicas.Null {
+		result.ElasticsearchReplicas = prior.ElasticsearchReplicas
+	}
+	result.ElasticsearchCACert = settings.CACert
+	if settings.CACert.Null {
+		result.ElasticsearchCACert = prior.ElasticsearchCACert
+	}
+	result.ElasticsearchVerifyTLS = settings.VerifyTLS
+	if settings.VerifyTLS.Null {
+		result.ElasticsearchVerifyTLS = prior.ElasticsearchVerifyTLS
+	}
+
+	// computed settings the API did not return are unset
+	if result.ElasticsearchUsername.Unknown {
+		result.ElasticsearchUsername = types.String{Null: true}
+	}
+	if result.ElasticsearchShards.Unknown {
+		result.ElasticsearchShards = types.Int64{Null: true}
+	}
+	if result.ElasticsearchReplicas.Unknown {
+		result.ElasticsearchReplicas = types.Int64{Null: true}
+	}
+	if result.ElasticsearchCACert.Unknown {
+		result.ElasticsearchCACert = types.String{Null: true}
+	}
+	if result.ElasticsearchVerifyTLS.Unknown {
+		result.ElasticsearchVerifyTLS = types.Bool{Null: true}
+	}
+}
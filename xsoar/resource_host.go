@@ -3,18 +3,21 @@ package xsoar
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"golang.org/x/crypto/ssh"
-	"hash/crc64"
 	"io"
 	"log"
-	"math/rand"
 	"net/http"
+	"os"
 	"strings"
+	"terraform-provider-xsoar/internal/client"
 	"time"
 )
 
@@ -51,7 +54,68 @@ func (r resourceHostType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagn
 				Optional:      true,
 				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
 			},
-			// todo: add in other elastic settings, especially index prefix
+			"elasticsearch_username": {
+				Type:          types.StringType,
+				Optional:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"elasticsearch_password": {
+				Type:          types.StringType,
+				Optional:      true,
+				Sensitive:     true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"elasticsearch_api_key": {
+				Type:          types.StringType,
+				Optional:      true,
+				Sensitive:     true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"elastic_index_prefix": {
+				Type:          types.StringType,
+				Computed:      true,
+				Optional:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"elasticsearch_ca_cert": {
+				Type:          types.StringType,
+				Optional:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"elasticsearch_verify_tls": {
+				Type:          types.BoolType,
+				Optional:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"server_version": {
+				Type:          types.StringType,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.UseStateForUnknown()),
+			},
+			"target_version": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"status": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"last_heartbeat": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"accounts": {
+				Type:     types.SetType{ElemType: types.StringType},
+				Computed: true,
+			},
+			"check_service": {
+				Type:     types.BoolType,
+				Optional: true,
+			},
+			"service_status": {
+				Type:     types.StringType,
+				Computed: true,
+			},
 			"server_url": {
 				Type:     types.StringType,
 				Required: true,
@@ -62,17 +126,176 @@ func (r resourceHostType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagn
 			},
 			"ssh_key": {
 				Type:      types.StringType,
-				Required:  true,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_key_passphrase": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_certificate": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"ssh_password": {
+				Type:      types.StringType,
+				Optional:  true,
 				Sensitive: true,
 			},
+			"ssh_use_agent": {
+				Type:     types.BoolType,
+				Optional: true,
+			},
+			"host_key": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"known_hosts_file": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"observed_host_key": {
+				Type:          types.StringType,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.UseStateForUnknown()),
+			},
 			"installation_timeout": {
 				Type:     types.Int64Type,
 				Optional: true,
 			},
+			"lock_timeout": {
+				Type:     types.Int64Type,
+				Optional: true,
+			},
+			"lock_ttl": {
+				Type:     types.Int64Type,
+				Optional: true,
+			},
 			"extra_flags": {
+				Type:               types.ListType{ElemType: types.StringType},
+				Optional:           true,
+				DeprecationMessage: "Use installer_options.extra_flags instead.",
+				Validators:         []tfsdk.AttributeValidator{installerFlagsValidator{}},
+			},
+			"installer_transfer": {
+				Type:       types.StringType,
+				Optional:   true,
+				Validators: []tfsdk.AttributeValidator{stringOneOf("pull", "push")},
+			},
+			"destroy_mode": {
+				Type:       types.StringType,
+				Optional:   true,
+				Validators: []tfsdk.AttributeValidator{stringOneOf("purge", "deregister_only", "skip")},
+			},
+			"license_file": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"post_install_commands": {
 				Type:     types.ListType{ElemType: types.StringType},
 				Optional: true,
 			},
+			"post_install_checksum": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"accounts_on_destroy": {
+				Type:       types.StringType,
+				Optional:   true,
+				Validators: []tfsdk.AttributeValidator{stringOneOf("ignore", "refuse", "move")},
+			},
+			"move_accounts_to": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"installer_options": {
+				NestingMode: tfsdk.BlockNestingModeList,
+				MaxItems:    1,
+				Attributes: map[string]tfsdk.Attribute{
+					"temp_folder": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"external_address": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"tools": {
+						Type:     types.BoolType,
+						Optional: true,
+					},
+					"docker": {
+						Type:     types.BoolType,
+						Optional: true,
+					},
+					"offline": {
+						Type:     types.BoolType,
+						Optional: true,
+					},
+					"extra_flags": {
+						Type:       types.ListType{ElemType: types.StringType},
+						Optional:   true,
+						Validators: []tfsdk.AttributeValidator{installerFlagsValidator{}},
+					},
+				},
+			},
+			"files": {
+				NestingMode: tfsdk.BlockNestingModeList,
+				Attributes: map[string]tfsdk.Attribute{
+					"source": {
+						Type:     types.StringType,
+						Required: true,
+					},
+					"destination": {
+						Type:     types.StringType,
+						Required: true,
+					},
+					"owner": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"mode": {
+						Type:       types.StringType,
+						Optional:   true,
+						Validators: []tfsdk.AttributeValidator{stringMatches(fileModePattern, "an octal file mode such as 0644")},
+					},
+				},
+			},
+			"preflight": {
+				NestingMode: tfsdk.BlockNestingModeList,
+				MaxItems:    1,
+				Attributes: map[string]tfsdk.Attribute{
+					"min_disk_gb": {
+						Type:     types.Int64Type,
+						Optional: true,
+					},
+					"min_memory_mb": {
+						Type:     types.Int64Type,
+						Optional: true,
+					},
+					"min_cpus": {
+						Type:     types.Int64Type,
+						Optional: true,
+					},
+					"require_container_runtime": {
+						Type:     types.BoolType,
+						Optional: true,
+					},
+					"allow_existing_installation": {
+						Type:     types.BoolType,
+						Optional: true,
+					},
+					"check_nfs_mount": {
+						Type:     types.BoolType,
+						Optional: true,
+					},
+				},
+			},
+			// jump hosts, in the order they are connected through
+			"bastion": bastionBlock(),
 		},
 	}, nil
 }
@@ -88,6 +311,68 @@ type resourceHost struct {
 	p provider
 }
 
+// ValidateConfig checks the SSH credentials can be used before anything is applied
+func (r resourceHost) ValidateConfig(ctx context.Context, req tfsdk.ValidateResourceConfigRequest, resp *tfsdk.ValidateResourceConfigResponse) {
+	var config Host
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.AccountsOnDestroy.Value == "move" && config.MoveAccountsTo.Null {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("move_accounts_to"),
+			"Missing move_accounts_to",
+			"move_accounts_to must be set when accounts_on_destroy is move",
+		)
+	}
+	resp.Diagnostics.Append(validateSSHConfig(path.Empty(), config.SSHKey, config.SSHKeyPassphrase, config.SSHCertificate, config.SSHPassword, config.SSHUseAgent)...)
+	resp.Diagnostics.Append(validateBastions(config.Bastions)...)
+}
+
+// ModifyPlan marks server_version as changing when target_version asks for a different version
+func (r resourceHost) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	// nothing to change in place on create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan Host
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	var state Host
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.TargetVersion.Null && (plan.TargetVersion.Unknown || !versionMatches(state.ServerVersion.Value, plan.TargetVersion.Value)) {
+		diags = resp.Plan.SetAttribute(ctx, path.Root("server_version"), types.String{Unknown: true})
+		resp.Diagnostics.Append(diags...)
+	}
+
+	// post-install steps are re-run when anything they use changes, including the content of local files
+	if plan.LicenseFile.Unknown || plan.PostInstallCommands.Unknown {
+		return
+	}
+	checksum := types.String{Null: true}
+	if hasPostInstall(plan) {
+		value, err := postInstallChecksum(plan)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading post-install files",
+				"Could not read post-install files: "+err.Error(),
+			)
+			return
+		}
+		checksum = types.String{Value: value}
+	}
+	diags = resp.Plan.SetAttribute(ctx, path.Root("post_install_checksum"), checksum)
+	resp.Diagnostics.Append(diags...)
+}
+
 // Create a new resource
 func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
 	log.Println("Starting create")
@@ -106,144 +391,42 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	log.Printf("%+v\n", plan)
-
-	var isHA bool
-	if !plan.HAGroupName.Null && len(plan.HAGroupName.Value) > 0 {
-		isHA = true
-	} else {
-		isHA = false
-	}
 
-	var isElastic bool
-	if isHA || len(plan.ElasticsearchUrl.Value) > 0 {
-		isElastic = true
-	} else {
-		isElastic = false
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_host", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	// 1) connect to host server over ssh
-	apikey := r.p.data.Apikey
-	mainhost := r.p.data.MainHost
-	signer, _ := ssh.ParsePrivateKey([]byte(plan.SSHKey.Value))
-	clientConfig := ssh.ClientConfig{
-		User: plan.SSHUser.Value,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-	var conn *ssh.Client
-	var err error
-	err = resource.RetryContext(ctx, 300*time.Second, func() *resource.RetryError {
-		var conErr error
-		conn, conErr = ssh.Dial("tcp", plan.ServerUrl.Value, &clientConfig)
-		if conErr != nil {
-			return resource.RetryableError(fmt.Errorf("error connecting to host over ssh: " + conErr.Error()))
-		}
-		return nil
-	})
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating host",
-			fmt.Sprintf("Error creating host: %s", err.Error()),
-		)
+	conn, verifier, diags := connectSSH(ctx, plan.sshTarget(), "")
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 	defer conn.Close()
 
-	// 2) query main server with /host/build
-	var haGroup string
-	var httpResponse *http.Response
-	if isHA {
-		var haGroups []map[string]interface{}
-		var haGroupId string
-		log.Println("List ha groups")
-		haGroups, _, err = r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+	// Check the host can take the installation before changing anything
+	if checks := hostPreflightChecks(plan); len(checks) > 0 {
+		err := runPreflightChecks(ctx, conn, checks)
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error listing HA groups",
-				"Could not list HA groups: "+err.Error(),
+				"Pre-flight checks failed",
+				"Host "+plan.Name.Value+" is not ready for installation, "+err.Error(),
 			)
 			return
 		}
-		for _, group := range haGroups {
-			if group["name"].(string) == plan.HAGroupName.Value {
-				haGroupId = group["id"].(string)
-				haGroup = "/" + haGroupId
-			}
-		}
-		_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
-		if err != nil {
-			log.Println(err.Error())
-			body, _ := io.ReadAll(httpResponse.Body)
-			log.Printf("code: %d status: %s body: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body))
-			i := bytes.Index(body, []byte("Already building host for ha group"))
-			if i > -1 {
-				for true {
-					_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
-					if err == nil {
-						break
-					}
-				}
-			} else {
-				resp.Diagnostics.AddError(
-					"Error creating HA installer",
-					"Could not create HA installer: "+err.Error(),
-				)
-				return
-			}
-		}
-	} else {
-		_, httpResponse, err = r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
-		if err != nil {
-			log.Println(err.Error())
-			body, bodyErr := io.ReadAll(httpResponse.Body)
-			if bodyErr != nil {
-				log.Println("error reading body: " + bodyErr.Error())
-				return
-			}
-			log.Printf("code: %d status: %s body: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body))
-			i := bytes.Index(body, []byte("Already building host installer"))
-			if i > -1 {
-				for true {
-					_, httpResponse, err = r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
-					if err == nil {
-						break
-					}
-				}
-			} else {
-				resp.Diagnostics.AddError(
-					"Error creating host installer",
-					"Could not create host installer: "+err.Error(),
-				)
-				return
-			}
-		}
 	}
 
-	// 3) download installer
-	session, err := conn.NewSession()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating ssh session",
-			"Could not create ssh session: "+err.Error(),
-		)
+	// 2) query main server with /host/build
+	haGroupId, diags := r.buildInstaller(ctx, plan.HAGroupName.Value)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	defer session.Close()
 
-	insecure := ""
-	if r.p.data.Insecure.Value {
-		insecure = "-k"
-	}
-	cmd := fmt.Sprintf(
-		"sudo curl -s -o '/tmp/installer.sh' -H 'Authorization: %s' %s %s/host/download%s && "+
-			"sudo chmod +x /tmp/installer.sh",
-		apikey.Value, insecure, mainhost.Value, haGroup)
-	err = session.Run(cmd)
+	// 3) transfer installer to the host
+	err := r.transferInstaller(ctx, conn, plan.InstallerTransfer.Value, haGroupId)
 	if err != nil {
-
 		resp.Diagnostics.AddError(
 			"Error downloading installer",
 			"Could not download installer: "+err.Error(),
@@ -251,159 +434,100 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 		return
 	}
 
-	// 4) Check for lock
-	if !plan.NFSMount.Null {
-		// wait a random amount of time
-		crcTable := crc64.MakeTable(crc64.ISO)
-		seedInt := int64(crc64.Checksum([]byte(plan.Name.Value), crcTable))
-		log.Printf("generated seed: %d\n", seedInt)
-		randSource := rand.NewSource(seedInt)
-		nrand := rand.New(randSource)
-		randomTimeToWait := nrand.Intn(30) + 1
-		log.Printf("sleeping for %d seconds\n", randomTimeToWait)
-		time.Sleep(time.Duration(randomTimeToWait) * time.Second)
-		// attempt to place lock
-		session, err = conn.NewSession()
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error creating ssh session",
-				"Could not create ssh session: "+err.Error(),
-			)
-			return
-		}
-		defer session.Close()
-		err = session.Run(fmt.Sprintf(
-			`while [[ -f "%s/xsoar_host_install.lock" ]]; do sleep %d; done; sudo touch %s/xsoar_host_install.lock`,
-			plan.NFSMount.Value, randomTimeToWait, plan.NFSMount.Value,
-		))
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error waiting for lock file",
-				"Lock file error: "+err.Error(),
-			)
-			return
-		}
-	}
-
-	// 5) Execute installer
-	log.Println("Executing install")
-	session, err = conn.NewSession()
+	// 4) Take the install lock on the shared volume
+	installLock, err := installLockFor(ctx, conn, plan)
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error creating ssh session",
-			"Could not create ssh session: "+err.Error(),
+			"Error acquiring install lock",
+			"Could not acquire install lock: "+err.Error(),
 		)
 		return
 	}
-	defer session.Close()
-
-	var args = []string{
-		"-y",
-		"-external-address='" + plan.Name.Value + "'",
-	}
-	if isElastic && !isHA {
-		args = append(args, "-elasticsearch-url='"+plan.ElasticsearchUrl.Value+"'")
-	}
-	if isHA {
-		args = append(args, "-temp-folder='/tmp/demisto'", "-ha")
-	}
-	if !plan.ExtraFlags.Null {
-		var extraArgs []string
-		flagErr := plan.ExtraFlags.ElementsAs(ctx, &extraArgs, false)
-		if flagErr != nil {
-			resp.Diagnostics.AddError(
-				"Error extracting extra arguments",
-				fmt.Sprintf("Could not extract %s into extraArgs with error: %s", plan.ExtraFlags.Elems, flagErr),
-			)
-			return
-		}
-		log.Printf("extra args: %s\n", extraArgs)
-		// todo: there's a security flaw here where a user can inject arbitrary commands into the installer
-		args = append(args, extraArgs...)
+	if installLock != nil {
+		defer func() {
+			if lockErr := installLock.Release(ctx); lockErr != nil {
+				resp.Diagnostics.AddError(
+					"Error releasing install lock",
+					"Could not release install lock: "+lockErr.Error(),
+				)
+			}
+		}()
 	}
-	argsString := strings.Join(args, " ")
 
-	err = session.Run("sudo /tmp/installer.sh -- " + argsString)
-	log.Printf("args: %s", argsString)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error running installer",
-			"Could not run installer: "+err.Error(),
-		)
-		log.Println("remove lock file")
-		session, err = conn.NewSession()
-		defer session.Close()
-		err = session.Run(fmt.Sprintf("sudo rm -f %s/xsoar_host_install.lock", plan.NFSMount.Value))
-		if err != nil {
-			log.Println("could not remove lock file")
-			resp.Diagnostics.AddError(
-				"Error removing lock file",
-				"Could not remove lock file: "+err.Error(),
-			)
-		}
+	// 5) Execute installer
+	log.Println("Executing install")
+	resp.Diagnostics.Append(runInstaller(ctx, conn, plan, "installer")...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	// Verify host details
 	log.Println("Verifying host details")
-	var host = make(map[string]interface{})
-	c1 := make(chan map[string]interface{}, 1)
-	go func() {
-		for len(host) == 0 || host["hostGroupId"] == "" {
-			host, _, _ = r.p.client.DefaultApi.GetHost(ctx, plan.Name.Value).Execute()
-			time.Sleep(time.Second)
-		}
-		c1 <- host
-	}()
+	var host *client.Host
 	timeout := time.Duration(300)
 	if !plan.InstallationTimeout.Null {
 		timeout = time.Duration(plan.InstallationTimeout.Value)
 	}
-	select {
-	case _ = <-c1:
-		log.Println(host)
-		break
-	case <-time.After(timeout * time.Second):
+	err = resource.RetryContext(ctx, timeout*time.Second, func() *resource.RetryError {
+		var getErr error
+		host, getErr = r.p.api.FindHost(ctx, plan.Name.Value)
+		if getErr != nil {
+			return resource.RetryableError(getErr)
+		}
+		if host == nil || host.HostGroupId == "" {
+			return resource.RetryableError(fmt.Errorf("host %s is not registered yet", plan.Name.Value))
+		}
+		return nil
+	})
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting host",
-			"Could not get host before timeout",
+			"Could not get host before timeout: "+err.Error(),
 		)
 		return
 	}
-	// delete lock file
-	if !plan.NFSMount.Null {
-		session, err = conn.NewSession()
+	// release the install lock
+	if installLock != nil {
+		err = installLock.Release(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error releasing install lock",
+				"Could not release install lock: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	// Run post-install steps now the host is registered
+	postInstallChecksumValue := types.String{Null: true}
+	if hasPostInstall(plan) {
+		checksum, err := postInstallChecksum(plan)
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error creating ssh session",
-				"Could not create ssh session: "+err.Error(),
+				"Error reading post-install files",
+				"Could not read post-install files: "+err.Error(),
 			)
 			return
 		}
-		defer session.Close()
-		err = session.Run(fmt.Sprintf(`sudo rm %s/xsoar_host_install.lock`, plan.NFSMount.Value))
+		err = runPostInstall(ctx, conn, plan)
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error deleting lock file",
-				"Could not delete lock file: "+err.Error(),
+				"Error running post-install steps",
+				"Could not run post-install steps: "+err.Error(),
 			)
 			return
 		}
+		postInstallChecksumValue = types.String{Value: checksum}
 	}
 
 	// Map response body to resource schema attribute
-	var hostName = host["host"].(string)
-	var hostId = host["id"].(string)
-	var hostGroupId = host["hostGroupId"].(string)
+	var hostName = host.Host
+	var hostId = host.Id
+	var hostGroupId = host.HostGroupId
 
-	haGroupName, httpResponse, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
+	haGroupName, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
 	if err != nil {
 		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
 		resp.Diagnostics.AddError(
 			"Error getting HA group",
 			"Could not get HA group: "+err.Error(),
@@ -413,28 +537,88 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 
 	var result Host
 	result = Host{
-		Name:                types.String{Value: hostName},
-		Id:                  types.String{Value: hostId},
-		InstallationTimeout: plan.InstallationTimeout,
-		ExtraFlags:          plan.ExtraFlags,
-		NFSMount:            plan.NFSMount,
-		ServerUrl:           plan.ServerUrl,
-		SSHUser:             plan.SSHUser,
-		SSHKey:              plan.SSHKey,
+		Name:                   types.String{Value: hostName},
+		Id:                     types.String{Value: hostId},
+		InstallationTimeout:    plan.InstallationTimeout,
+		LockTimeout:            plan.LockTimeout,
+		LockTTL:                plan.LockTTL,
+		ExtraFlags:             plan.ExtraFlags,
+		InstallerTransfer:      plan.InstallerTransfer,
+		InstallerOptions:       plan.InstallerOptions,
+		NFSMount:               plan.NFSMount,
+		ServerUrl:              plan.ServerUrl,
+		SSHUser:                plan.SSHUser,
+		SSHKey:                 plan.SSHKey,
+		SSHKeyPassphrase:       plan.SSHKeyPassphrase,
+		SSHCertificate:         plan.SSHCertificate,
+		SSHPassword:            plan.SSHPassword,
+		SSHUseAgent:            plan.SSHUseAgent,
+		HostKey:                plan.HostKey,
+		KnownHostsFile:         plan.KnownHostsFile,
+		ObservedHostKey:        types.String{Value: verifier.Observed},
+		TargetVersion:          plan.TargetVersion,
+		DestroyMode:            plan.DestroyMode,
+		AccountsOnDestroy:      plan.AccountsOnDestroy,
+		MoveAccountsTo:         plan.MoveAccountsTo,
+		ElasticsearchUsername:  plan.ElasticsearchUsername,
+		ElasticsearchPassword:  plan.ElasticsearchPassword,
+		ElasticsearchApiKey:    plan.ElasticsearchApiKey,
+		ElasticsearchCACert:    plan.ElasticsearchCACert,
+		ElasticsearchVerifyTLS: plan.ElasticsearchVerifyTLS,
+		LicenseFile:            plan.LicenseFile,
+		Files:                  plan.Files,
+		PostInstallCommands:    plan.PostInstallCommands,
+		PostInstallChecksum:    postInstallChecksumValue,
+		Preflight:              plan.Preflight,
+		Bastions:               conn.observedBastions(plan.Bastions),
+	}
+
+	if version := host.ReportedVersion(); len(version) > 0 {
+		result.ServerVersion.Value = version
+		if !plan.TargetVersion.Null && !versionMatches(version, plan.TargetVersion.Value) {
+			resp.Diagnostics.AddWarning(
+				"Host version differs from target_version",
+				fmt.Sprintf("The installer from the main server installed version %s, but target_version is %s", version, plan.TargetVersion.Value),
+			)
+		}
+	} else {
+		result.ServerVersion.Null = true
 	}
 
-	if host["host"].(string) != haGroupName.GetName() {
+	if host.Host != haGroupName.GetName() {
 		result.HAGroupName.Value = haGroupName.GetName()
 	} else {
 		result.HAGroupName.Null = true
 	}
 
-	if len(host["elasticsearchAddress"].(string)) > 0 {
-		result.ElasticsearchUrl.Value = host["elasticsearchAddress"].(string)
+	if len(host.ElasticsearchAddress) > 0 {
+		result.ElasticsearchUrl.Value = host.ElasticsearchAddress
 	} else {
 		result.ElasticsearchUrl.Null = true
 	}
 
+	if prefix := host.ElasticIndexPrefix; len(prefix) > 0 {
+		result.ElasticIndexPrefix = types.String{Value: prefix}
+	} else if !plan.ElasticIndexPrefix.Unknown {
+		result.ElasticIndexPrefix = plan.ElasticIndexPrefix
+	} else {
+		result.ElasticIndexPrefix = types.String{Null: true}
+	}
+
+	err = r.setHostStatus(ctx, host, &result)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting host status",
+			"Could not get host status: "+err.Error(),
+		)
+		return
+	}
+	result.CheckService = plan.CheckService
+	result.ServiceStatus.Null = true
+	if plan.CheckService.Value {
+		result.ServiceStatus = types.String{Value: serviceStatus(ctx, conn)}
+	}
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -453,47 +637,29 @@ func (r resourceHost) Read(ctx context.Context, req tfsdk.ReadResourceRequest, r
 		return
 	}
 
-	var host map[string]interface{}
-	var err error
-	c1 := make(chan map[string]interface{}, 1)
-	go func() {
-		for host == nil {
-			host, _, err = r.p.client.DefaultApi.GetHost(ctx, state.Name.Value).Execute()
-			if err != nil {
-				resp.Diagnostics.AddError(
-					"Error listing HA groups",
-					"Could not list HA groups: "+err.Error(),
-				)
-				return
-			}
-			time.Sleep(time.Second)
-		}
-		c1 <- host
-	}()
-	select {
-	case _ = <-c1:
-		break
-	case <-time.After(60 * time.Second):
+	host, err := r.p.api.FindHost(ctx, state.Name.Value)
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting host",
-			"Could not get host before timeout",
+			"Could not get host: "+err.Error(),
 		)
 		return
 	}
+	// the host was removed outside of Terraform
+	if host == nil {
+		log.Printf("host %s not found, removing from state\n", state.Name.Value)
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
 	// Map response body to resource schema attribute
-	var hostName = host["host"].(string)
-	var hostId = host["id"].(string)
-	var hostGroupId = host["hostGroupId"].(string)
+	var hostName = host.Host
+	var hostId = host.Id
+	var hostGroupId = host.HostGroupId
 
-	haGroupName, httpResponse, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
+	haGroupName, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
 	if err != nil {
 		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
 		resp.Diagnostics.AddError(
 			"Error getting HA group",
 			"Could not get HA group: "+err.Error(),
@@ -503,33 +669,85 @@ func (r resourceHost) Read(ctx context.Context, req tfsdk.ReadResourceRequest, r
 
 	var result Host
 	result = Host{
-		Name:                types.String{Value: hostName},
-		Id:                  types.String{Value: hostId},
-		InstallationTimeout: state.InstallationTimeout,
-		ExtraFlags:          state.ExtraFlags,
-		NFSMount:            state.NFSMount,
-		ServerUrl:           state.ServerUrl,
-		SSHUser:             state.SSHUser,
-		SSHKey:              state.SSHKey,
+		Name:                   types.String{Value: hostName},
+		Id:                     types.String{Value: hostId},
+		InstallationTimeout:    state.InstallationTimeout,
+		LockTimeout:            state.LockTimeout,
+		LockTTL:                state.LockTTL,
+		ExtraFlags:             state.ExtraFlags,
+		InstallerTransfer:      state.InstallerTransfer,
+		InstallerOptions:       state.InstallerOptions,
+		NFSMount:               state.NFSMount,
+		ServerUrl:              state.ServerUrl,
+		SSHUser:                state.SSHUser,
+		SSHKey:                 state.SSHKey,
+		SSHKeyPassphrase:       state.SSHKeyPassphrase,
+		SSHCertificate:         state.SSHCertificate,
+		SSHPassword:            state.SSHPassword,
+		SSHUseAgent:            state.SSHUseAgent,
+		HostKey:                state.HostKey,
+		KnownHostsFile:         state.KnownHostsFile,
+		ObservedHostKey:        state.ObservedHostKey,
+		TargetVersion:          state.TargetVersion,
+		DestroyMode:            state.DestroyMode,
+		AccountsOnDestroy:      state.AccountsOnDestroy,
+		MoveAccountsTo:         state.MoveAccountsTo,
+		ElasticsearchUsername:  state.ElasticsearchUsername,
+		ElasticsearchPassword:  state.ElasticsearchPassword,
+		ElasticsearchApiKey:    state.ElasticsearchApiKey,
+		ElasticsearchCACert:    state.ElasticsearchCACert,
+		ElasticsearchVerifyTLS: state.ElasticsearchVerifyTLS,
+		LicenseFile:            state.LicenseFile,
+		Files:                  state.Files,
+		PostInstallCommands:    state.PostInstallCommands,
+		PostInstallChecksum:    state.PostInstallChecksum,
+		Preflight:              state.Preflight,
+		Bastions:               state.Bastions,
+	}
+
+	if version := host.ReportedVersion(); len(version) > 0 {
+		result.ServerVersion.Value = version
+	} else {
+		result.ServerVersion.Null = true
 	}
 
-	if host["host"].(string) != haGroupName.GetName() {
+	if host.Host != haGroupName.GetName() {
 		result.HAGroupName.Value = haGroupName.GetName()
 	} else {
 		result.HAGroupName.Null = true
 	}
 
-	if len(host["elasticsearchAddress"].(string)) > 0 {
-		result.ElasticsearchUrl.Value = host["elasticsearchAddress"].(string)
+	if len(host.ElasticsearchAddress) > 0 {
+		result.ElasticsearchUrl.Value = host.ElasticsearchAddress
 	} else {
 		result.ElasticsearchUrl.Null = true
 	}
 
-	// Generate resource state struct
-	diags = resp.State.Set(ctx, result)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if prefix := host.ElasticIndexPrefix; len(prefix) > 0 {
+		result.ElasticIndexPrefix = types.String{Value: prefix}
+	} else {
+		result.ElasticIndexPrefix = state.ElasticIndexPrefix
+	}
+
+	err = r.setHostStatus(ctx, host, &result)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting host status",
+			"Could not get host status: "+err.Error(),
+		)
+		return
+	}
+	result.CheckService = state.CheckService
+	result.ServiceStatus.Null = true
+	if state.CheckService.Value {
+		result.ServiceStatus = r.checkService(ctx, state, &resp.Diagnostics)
+	}
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 }
 
@@ -543,198 +761,138 @@ func (r resourceHost) Update(ctx context.Context, req tfsdk.UpdateResourceReques
 		return
 	}
 
-	// Get current state
-	var state Host
-	diags = req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_host", multiTenantMinVersion, true)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Most attributes require a resource to be recreated,
-	// the only attributes which are changeable are ones not available through the API about the host itself
-	result := plan
-	result.Id = state.Id
-
-	// Set state
-	diags = resp.State.Set(ctx, result)
+	// Get current state
+	var state Host
+	diags = req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-}
 
-// Delete resource
-func (r resourceHost) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
-	var state Host
-	diags := req.State.Get(ctx, &state)
+	// An imported host, and bastions added since the last apply, have their host keys recorded before anything else
+	// connects
+	observedHostKey, bastions, diags := recordHostKeys(ctx, plan.sshTarget(), state.ObservedHostKey.Value)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	plan.Bastions = bastions
+	plan.ObservedHostKey = types.String{Value: observedHostKey}
 
-	var isHA bool
-	if !state.HAGroupName.Null && len(state.HAGroupName.Value) > 0 {
-		isHA = true
-	} else {
-		isHA = false
+	// Most attributes require a resource to be recreated,
+	// the only attributes which are changeable are ones not available through the API about the host itself
+	result := plan
+	result.Id = state.Id
+	result.ServerVersion = state.ServerVersion
+	result.Status = state.Status
+	result.LastHeartbeat = state.LastHeartbeat
+	result.Accounts = state.Accounts
+	result.ServiceStatus.Null = true
+	if plan.CheckService.Value {
+		result.ServiceStatus = r.checkService(ctx, plan, &resp.Diagnostics)
 	}
 
-	// Delete Host
-	// 1) connect to host server over ssh
-	apikey := r.p.data.Apikey
-	mainhost := r.p.data.MainHost
-	signer, _ := ssh.ParsePrivateKey([]byte(state.SSHKey.Value))
-	clientConfig := ssh.ClientConfig{
-		User: state.SSHUser.Value,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-	var conn *ssh.Client
-	var err error
-	err = resource.RetryContext(ctx, 300*time.Second, func() *resource.RetryError {
-		var conErr error
-		conn, conErr = ssh.Dial("tcp", state.ServerUrl.Value, &clientConfig)
-		if conErr != nil {
-			return resource.RetryableError(fmt.Errorf("error connecting to host over ssh: " + conErr.Error()))
+	// The version can be changed in place by running the current installer over the existing installation
+	if !plan.TargetVersion.Null && !versionMatches(state.ServerVersion.Value, plan.TargetVersion.Value) {
+		version, diags := r.upgrade(ctx, plan, observedHostKey)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
-		return nil
-	})
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting host",
-			"Could not delete host: "+err.Error(),
-		)
-		return
+		result.ServerVersion = types.String{Value: version}
 	}
-	defer conn.Close()
 
-	// 2) query main server with /host/build
-	var haGroup string
-	var httpResponse *http.Response
-	if isHA {
-		var haGroups []map[string]interface{}
-		var haGroupId string
-		log.Println("List ha groups")
-		haGroups, _, err = r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+	// Post-install steps are run again when they or the files they use changed, see ModifyPlan
+	result.PostInstallChecksum = state.PostInstallChecksum
+	if hasPostInstall(plan) && (plan.PostInstallChecksum.Unknown || plan.PostInstallChecksum.Value != state.PostInstallChecksum.Value) {
+		checksum, err := postInstallChecksum(plan)
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error listing HA groups",
-				"Could not list HA groups: "+err.Error(),
+				"Error reading post-install files",
+				"Could not read post-install files: "+err.Error(),
 			)
 			return
 		}
-		for _, group := range haGroups {
-			if group["name"].(string) == state.HAGroupName.Value {
-				haGroupId = group["id"].(string)
-				haGroup = "/" + haGroupId
-			}
-		}
-		_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
-		if err != nil {
-			body, bodyErr := io.ReadAll(httpResponse.Body)
-			if bodyErr != nil {
-				log.Println("error reading body: " + bodyErr.Error())
-				return
-			}
-			log.Printf("code: %d status: %s body: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body))
-			i := bytes.Index(body, []byte("Already building host for ha group"))
-			if i > -1 {
-				for true {
-					_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
-					if err == nil {
-						break
-					}
-				}
-			} else {
-				resp.Diagnostics.AddError(
-					"Error creating HA installer",
-					"Could not create HA installer: "+err.Error(),
-				)
-				return
-			}
+		conn, _, diags := connectSSH(ctx, plan.sshTarget(), observedHostKey)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
-	} else {
-		_, _, err = r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
+		defer conn.Close()
+		err = runPostInstall(ctx, conn, plan)
 		if err != nil {
-			body, bodyErr := io.ReadAll(httpResponse.Body)
-			if bodyErr != nil {
-				log.Println("error reading body: " + bodyErr.Error())
-				return
-			}
-			log.Printf("code: %d status: %s body: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body))
-			i := bytes.Index(body, []byte("Already building host for ha group"))
-			if i > -1 {
-				for true {
-					_, httpResponse, err = r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
-					if err == nil {
-						break
-					}
-				}
-			} else {
-				resp.Diagnostics.AddError(
-					"Error creating host installer",
-					"Could not create host installer: "+err.Error(),
-				)
-				return
-			}
+			resp.Diagnostics.AddError(
+				"Error running post-install steps",
+				"Could not run post-install steps: "+err.Error(),
+			)
+			return
 		}
+		result.PostInstallChecksum = types.String{Value: checksum}
+	} else if !hasPostInstall(plan) {
+		result.PostInstallChecksum = types.String{Null: true}
 	}
 
-	// 3) download installer
-	session, err := conn.NewSession()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating ssh session",
-			"Could not create ssh session: "+err.Error(),
-		)
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	defer session.Close()
+}
 
-	insecure := ""
-	if r.p.data.Insecure.Value {
-		insecure = "-k"
-	}
-	cmd := fmt.Sprintf(
-		"sudo curl -s -o '/tmp/installer.sh' -H 'Authorization: %s' %s %s/host/download%s && "+
-			"sudo chmod +x /tmp/installer.sh",
-		apikey.Value, insecure, mainhost.Value, haGroup)
-	err = session.Run(cmd)
-	if err != nil {
-		fmt.Println(cmd)
-		resp.Diagnostics.AddError(
-			"Error downloading installer",
-			"Could not download installer: "+err.Error(),
-		)
+// Delete resource
+func (r resourceHost) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	var state Host
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// 4) Execute installer
-	session, err = conn.NewSession()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating ssh session",
-			"Could not create ssh session: "+err.Error(),
-		)
+	destroyMode := state.DestroyMode.Value
+	if state.DestroyMode.Null || len(destroyMode) == 0 {
+		destroyMode = "purge"
+	}
+	if destroyMode == "skip" {
+		tflog.SubsystemInfo(withLogSubsystem(ctx, apiLogSubsystem), apiLogSubsystem, "destroy_mode is skip, leaving host in place", map[string]interface{}{
+			"host": state.Name.Value,
+		})
+		resp.State.RemoveResource(ctx)
 		return
 	}
-	defer session.Close()
 
-	err = session.Run("sudo /tmp/installer.sh -- -purge -y")
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error running installer",
-			"Could not run installer: "+err.Error(),
-		)
+	// Make sure no accounts are left without a host
+	diags = r.releaseAccounts(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	// Uninstall from the host, not needed if it is only removed from main
+	if destroyMode == "purge" {
+		diags = r.purge(ctx, state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Delete host from main
-	_, _, err = r.p.client.DefaultApi.DeleteHost(ctx, state.Id.Value).Execute()
+	_, _, err := r.p.client.DefaultApi.DeleteHost(ctx, state.Id.Value).Execute()
 	if err != nil {
+		host, getErr := r.p.api.FindHost(ctx, state.Name.Value)
+		if getErr == nil && host == nil {
+			tflog.SubsystemInfo(withLogSubsystem(ctx, apiLogSubsystem), apiLogSubsystem, "host already removed from the main server", map[string]interface{}{
+				"host": state.Name.Value,
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Error deleting host",
 			"Could not delete host: "+err.Error(),
@@ -747,40 +905,37 @@ func (r resourceHost) Delete(ctx context.Context, req tfsdk.DeleteResourceReques
 }
 
 func (r resourceHost) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_host", multiTenantMinVersion, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var diags diag.Diagnostics
 	name := req.ID
 
-	var host map[string]interface{}
-	var err error
-	c1 := make(chan map[string]interface{}, 1)
-	go func() {
-		for host == nil {
-			host, _, err = r.p.client.DefaultApi.GetHost(ctx, name).Execute()
-			if err != nil {
-				resp.Diagnostics.AddError(
-					"Error getting host",
-					"Could not get host: "+err.Error(),
-				)
-				return
-			}
-			time.Sleep(time.Second)
-		}
-		c1 <- host
-	}()
-	select {
-	case _ = <-c1:
-		break
-	case <-time.After(60 * time.Second):
+	var host *client.Host
+	err := resource.RetryContext(ctx, 60*time.Second, func() *resource.RetryError {
+		var getErr error
+		host, getErr = r.p.api.FindHost(ctx, name)
+		if getErr != nil {
+			return resource.NonRetryableError(getErr)
+		}
+		if host == nil {
+			return resource.RetryableError(fmt.Errorf("host %s is not registered", name))
+		}
+		return nil
+	})
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting host",
-			"Could not get host before timeout",
+			"Could not get host: "+err.Error(),
 		)
 		return
 	}
 
-	var hostName = host["host"].(string)
-	var hostId = host["id"].(string)
-	var hostGroupId = host["hostGroupId"].(string)
+	var hostName = host.Host
+	var hostId = host.Id
+	var hostGroupId = host.HostGroupId
 
 	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
 	if err != nil {
@@ -794,28 +949,73 @@ func (r resourceHost) ImportState(ctx context.Context, req tfsdk.ImportResourceS
 	// Map response body to resource schema attribute
 	var result Host
 	result = Host{
-		Name: types.String{Value: hostName},
-		Id:   types.String{Value: hostId},
+		Name:                   types.String{Value: hostName},
+		Id:                     types.String{Value: hostId},
+		SSHKeyPassphrase:       types.String{Null: true},
+		SSHCertificate:         types.String{Null: true},
+		SSHPassword:            types.String{Null: true},
+		SSHUseAgent:            types.Bool{Null: true},
+		HostKey:                types.String{Null: true},
+		KnownHostsFile:         types.String{Null: true},
+		ObservedHostKey:        types.String{Null: true},
+		ServerVersion:          types.String{Null: true},
+		TargetVersion:          types.String{Null: true},
+		CheckService:           types.Bool{Null: true},
+		ServiceStatus:          types.String{Null: true},
+		DestroyMode:            types.String{Null: true},
+		AccountsOnDestroy:      types.String{Null: true},
+		MoveAccountsTo:         types.String{Null: true},
+		ElasticsearchUsername:  types.String{Null: true},
+		ElasticsearchPassword:  types.String{Null: true},
+		ElasticsearchApiKey:    types.String{Null: true},
+		ElasticsearchCACert:    types.String{Null: true},
+		ElasticsearchVerifyTLS: types.Bool{Null: true},
+		LicenseFile:            types.String{Null: true},
+		Files:                  []HostFile{},
+		PostInstallCommands:    types.List{ElemType: types.StringType, Null: true},
+		PostInstallChecksum:    types.String{Null: true},
+		Preflight:              []PreflightChecks{},
+		Bastions:               []Bastion{},
+	}
+
+	err = r.setHostStatus(ctx, host, &result)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting host status",
+			"Could not get host status: "+err.Error(),
+		)
+		return
+	}
+
+	if version := host.ReportedVersion(); len(version) > 0 {
+		result.ServerVersion.Value = version
+		result.ServerVersion.Null = false
 	}
 
 	var isHA = false
-	if host["host"].(string) != haGroup.GetName() {
+	if host.Host != haGroup.GetName() {
 		isHA = true
 		result.HAGroupName.Value = haGroup.GetName()
 	} else {
 		result.HAGroupName.Null = true
 	}
 
-	if len(host["elasticsearchAddress"].(string)) > 0 {
+	if len(host.ElasticsearchAddress) > 0 {
 		if isHA {
 			result.ElasticsearchUrl.Null = true
 		} else {
-			result.ElasticsearchUrl.Value = host["elasticsearchAddress"].(string)
+			result.ElasticsearchUrl.Value = host.ElasticsearchAddress
 		}
 	} else {
 		result.ElasticsearchUrl.Null = true
 	}
 
+	if prefix := host.ElasticIndexPrefix; len(prefix) > 0 && !isHA {
+		result.ElasticIndexPrefix = types.String{Value: prefix}
+	} else {
+		result.ElasticIndexPrefix = types.String{Null: true}
+	}
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -823,3 +1023,616 @@ func (r resourceHost) ImportState(ctx context.Context, req tfsdk.ImportResourceS
 		return
 	}
 }
+
+// hostElasticsearchCAPath is where elasticsearch_ca_cert is placed on the host for the installer
+const hostElasticsearchCAPath = "/usr/local/share/xsoar/elasticsearch-ca.pem"
+
+// installerSecretFlags are the installer flags whose values are not logged
+var installerSecretFlags = []string{"-elasticsearch-password=", "-elasticsearch-api-key="}
+
+// isInstallerSecret reports whether arg is an installer flag with a secret value
+func isInstallerSecret(arg string) bool {
+	for _, flag := range installerSecretFlags {
+		if strings.HasPrefix(arg, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactInstallerArgs returns the arguments for logging, with secret values masked
+func redactInstallerArgs(args []string) string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = arg
+		if isInstallerSecret(arg) {
+			redacted[i] = arg[:strings.Index(arg, "=")+1] + "***"
+		}
+	}
+	return shellJoin(redacted)
+}
+
+// runInstaller runs the installer placed at /tmp/installer.sh with the arguments for host, after placing its
+// Elasticsearch CA certificate. It is used both to install and to upgrade, so an upgrade keeps the configuration.
+func runInstaller(ctx context.Context, conn *sshConnection, host Host, step string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	isHA, isElastic := hostStorage(host)
+	if isElastic && !isHA && !host.ElasticsearchCACert.Null && host.ElasticsearchCACert.Value != "" {
+		ca := host.ElasticsearchCACert.Value
+		err := uploadHostContent(ctx, conn, strings.NewReader(ca), int64(len(ca)), hostElasticsearchCAPath, "root:root", "0644")
+		if err != nil {
+			diags.AddError(
+				"Error uploading Elasticsearch CA certificate",
+				"Could not upload Elasticsearch CA certificate: "+err.Error(),
+			)
+			return diags
+		}
+	}
+	secretsFile := fmt.Sprintf("/tmp/xsoar_installer_%d", time.Now().UnixNano())
+	cmd, secrets, args, err := installerCommand(ctx, host, secretsFile)
+	if err != nil {
+		diags.AddError(
+			"Error building installer arguments",
+			"Could not build installer arguments: "+err.Error(),
+		)
+		return diags
+	}
+	if secrets != "" {
+		err = uploadSSHFile(ctx, conn, strings.NewReader(secrets), int64(len(secrets)), secretsFile, 0600)
+		if err != nil {
+			diags.AddError(
+				"Error uploading installer arguments",
+				"Could not upload Elasticsearch credentials: "+err.Error(),
+			)
+			return diags
+		}
+	}
+
+	log.Printf("args: %s", redactInstallerArgs(args))
+	err = runSSHCommand(ctx, conn, step, cmd)
+	if err != nil {
+		diags.AddError(
+			"Error running installer",
+			"Could not run installer: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// hostStorage tells whether the host joins an HA group and whether it stores its data in Elasticsearch, which every
+// HA group does
+func hostStorage(host Host) (isHA bool, isElastic bool) {
+	isHA = !host.HAGroupName.Null && len(host.HAGroupName.Value) > 0
+	isElastic = isHA || len(host.ElasticsearchUrl.Value) > 0
+	return isHA, isElastic
+}
+
+// installerCommand returns the command running the installer at /tmp/installer.sh for host, and all its arguments for
+// logging. The secret arguments, see installerSecretFlags, are kept off the command line, where the process list and
+// the sudo log would show them: they are returned as a script to place at secretsFile, readable only by the SSH user,
+// which the command reads as root and removes. secrets is empty when there are none.
+func installerCommand(ctx context.Context, host Host, secretsFile string) (cmd string, secrets string, args []string, err error) {
+	isHA, isElastic := hostStorage(host)
+	args, err = installerArgs(ctx, host, isHA, isElastic)
+	if err != nil {
+		return "", "", nil, err
+	}
+	var public []string
+	var script strings.Builder
+	for _, arg := range args {
+		if isInstallerSecret(arg) {
+			fmt.Fprintf(&script, "set -- \"$@\" %s\n", shellQuote(arg))
+		} else {
+			public = append(public, arg)
+		}
+	}
+	if script.Len() == 0 {
+		return "sudo /tmp/installer.sh -- " + shellJoin(public), "", args, nil
+	}
+	cmd = fmt.Sprintf(
+		"sudo sh -c %s %s %s; rc=$?; rm -f %s; [ $rc -eq 0 ]",
+		shellQuote(`. "$0" && rm -f "$0" && exec /tmp/installer.sh -- "$@"`), shellQuote(secretsFile), shellJoin(public), shellQuote(secretsFile))
+	return cmd, script.String(), args, nil
+}
+
+// installerArgs builds the arguments passed to the installer. Values are not quoted here, see shellJoin.
+func installerArgs(ctx context.Context, host Host, isHA bool, isElastic bool) ([]string, error) {
+	externalAddress := host.Name.Value
+	tempFolder := ""
+	if isHA {
+		tempFolder = "/tmp/demisto"
+	}
+	// without an installer_options block every option is left to the installer
+	options := InstallerOptions{
+		TempFolder:      types.String{Null: true},
+		ExternalAddress: types.String{Null: true},
+		Tools:           types.Bool{Null: true},
+		Docker:          types.Bool{Null: true},
+		Offline:         types.Bool{Null: true},
+		ExtraFlags:      types.List{ElemType: types.StringType, Null: true},
+	}
+	if len(host.InstallerOptions) > 0 {
+		options = host.InstallerOptions[0]
+	}
+	if !options.ExternalAddress.Null && options.ExternalAddress.Value != "" {
+		externalAddress = options.ExternalAddress.Value
+	}
+	if !options.TempFolder.Null && options.TempFolder.Value != "" {
+		tempFolder = options.TempFolder.Value
+	}
+
+	var args = []string{
+		"-y",
+		"-external-address=" + externalAddress,
+	}
+	if isElastic && !isHA {
+		args = append(args, "-elasticsearch-url="+host.ElasticsearchUrl.Value)
+		if !host.ElasticsearchUsername.Null && host.ElasticsearchUsername.Value != "" {
+			args = append(args, "-elasticsearch-username="+host.ElasticsearchUsername.Value)
+		}
+		if !host.ElasticsearchPassword.Null && host.ElasticsearchPassword.Value != "" {
+			args = append(args, "-elasticsearch-password="+host.ElasticsearchPassword.Value)
+		}
+		if !host.ElasticsearchApiKey.Null && host.ElasticsearchApiKey.Value != "" {
+			args = append(args, "-elasticsearch-api-key="+host.ElasticsearchApiKey.Value)
+		}
+		if !host.ElasticIndexPrefix.Null && !host.ElasticIndexPrefix.Unknown && host.ElasticIndexPrefix.Value != "" {
+			args = append(args, "-elasticsearch-index-prefix="+host.ElasticIndexPrefix.Value)
+		}
+		if !host.ElasticsearchCACert.Null && host.ElasticsearchCACert.Value != "" {
+			args = append(args, "-elasticsearch-ca-cert="+hostElasticsearchCAPath)
+		}
+		if !host.ElasticsearchVerifyTLS.Null && !host.ElasticsearchVerifyTLS.Value {
+			args = append(args, "-elasticsearch-insecure")
+		}
+	}
+	if tempFolder != "" {
+		args = append(args, "-temp-folder="+tempFolder)
+	}
+	if isHA {
+		args = append(args, "-ha")
+	}
+	if !options.Tools.Null {
+		args = append(args, fmt.Sprintf("-tools=%t", options.Tools.Value))
+	}
+	if !options.Docker.Null {
+		args = append(args, fmt.Sprintf("-docker=%t", options.Docker.Value))
+	}
+	if options.Offline.Value {
+		args = append(args, "-offline")
+	}
+	for _, flags := range []types.List{host.ExtraFlags, options.ExtraFlags} {
+		if flags.Null {
+			continue
+		}
+		var extraArgs []string
+		diags := flags.ElementsAs(ctx, &extraArgs, false)
+		if diags.HasError() {
+			return nil, fmt.Errorf("could not read extra_flags")
+		}
+		for _, flag := range extraArgs {
+			if err := validateInstallerFlag(flag); err != nil {
+				return nil, err
+			}
+		}
+		args = append(args, extraArgs...)
+	}
+	return args, nil
+}
+
+// transferInstaller places the installer for the host, or for its HA group, at /tmp/installer.sh, see transferInstaller
+func (r resourceHost) transferInstaller(ctx context.Context, conn *sshConnection, mode string, haGroupId string) error {
+	downloadPath := "/host/download"
+	if haGroupId != "" {
+		downloadPath += "/" + haGroupId
+	}
+	return transferInstaller(ctx, r.p, conn, mode, downloadPath, "/tmp/installer.sh", func(ctx context.Context) (*os.File, error) {
+		if haGroupId != "" {
+			installer, _, err := r.p.client.DefaultApi.GetHAInstaller(ctx, haGroupId).Execute()
+			return installer, err
+		}
+		installer, _, err := r.p.client.DefaultApi.GetHostInstaller(ctx).Execute()
+		return installer, err
+	})
+}
+
+// purge uninstalls XSOAR from the host with the installer currently provided by the main server
+func (r resourceHost) purge(ctx context.Context, host Host) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// 1) connect to host server over ssh
+	conn, _, connDiags := connectSSH(ctx, host.sshTarget(), host.ObservedHostKey.Value)
+	diags.Append(connDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	defer conn.Close()
+
+	// 2) query main server with /host/build
+	haGroupId, buildDiags := r.buildInstaller(ctx, host.HAGroupName.Value)
+	diags.Append(buildDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	// 3) transfer installer to the host
+	err := r.transferInstaller(ctx, conn, host.InstallerTransfer.Value, haGroupId)
+	if err != nil {
+		diags.AddError(
+			"Error downloading installer",
+			"Could not download installer: "+err.Error(),
+		)
+		return diags
+	}
+
+	// 4) Execute installer
+	err = runSSHCommand(ctx, conn, "purge", "sudo /tmp/installer.sh -- -purge -y")
+	if err != nil {
+		diags.AddError(
+			"Error running installer",
+			"Could not run installer: "+err.Error(),
+		)
+		return diags
+	}
+	return diags
+}
+
+// releaseAccounts applies accounts_on_destroy to the accounts that would be left without a host once this host is
+// deleted. Accounts of an HA group that keeps other hosts are not affected.
+func (r resourceHost) releaseAccounts(ctx context.Context, host Host) diag.Diagnostics {
+	var diags diag.Diagnostics
+	mode := host.AccountsOnDestroy.Value
+	if host.AccountsOnDestroy.Null || len(mode) == 0 || mode == "ignore" {
+		return diags
+	}
+
+	record, err := r.p.api.FindHost(ctx, host.Name.Value)
+	if err != nil {
+		diags.AddError(
+			"Error getting host",
+			"Could not get host: "+err.Error(),
+		)
+		return diags
+	}
+	if record == nil {
+		return diags
+	}
+	hostGroupId := record.HostGroupId
+
+	hosts, err := r.p.api.ListHosts(ctx)
+	if err != nil {
+		diags.AddError(
+			"Error listing hosts",
+			"Could not list hosts: "+err.Error(),
+		)
+		return diags
+	}
+	for _, other := range hosts {
+		if other.HostGroupId == hostGroupId && other.Host != host.Name.Value {
+			tflog.SubsystemInfo(withLogSubsystem(ctx, apiLogSubsystem), apiLogSubsystem, "HA group has other members, accounts stay with the group", map[string]interface{}{
+				"host": host.Name.Value,
+			})
+			return diags
+		}
+	}
+
+	accounts, err := r.p.api.ListAccounts(ctx)
+	if err != nil {
+		diags.AddError(
+			"Error listing accounts",
+			"Could not list accounts: "+err.Error(),
+		)
+		return diags
+	}
+	var assigned []client.Account
+	var names []string
+	for _, account := range accounts {
+		if account.HostGroupId == hostGroupId {
+			assigned = append(assigned, account)
+			names = append(names, account.DisplayName)
+		}
+	}
+	if len(assigned) == 0 {
+		return diags
+	}
+
+	if mode == "refuse" {
+		diags.AddError(
+			"Host has accounts assigned",
+			fmt.Sprintf("Refusing to delete host %s while accounts are assigned to it: %s. Move the accounts to another host or HA group first, or set accounts_on_destroy to move.", host.Name.Value, strings.Join(names, ", ")),
+		)
+		return diags
+	}
+
+	// move the accounts to the target group, standalone hosts have a group named after the host
+	target, err := r.p.api.FindHAGroupByName(ctx, host.MoveAccountsTo.Value)
+	if err != nil {
+		diags.AddError(
+			"Error listing HA groups",
+			"Could not list HA groups: "+err.Error(),
+		)
+		return diags
+	}
+	if target == nil || target.Id == hostGroupId {
+		diags.AddError(
+			"Error moving accounts",
+			fmt.Sprintf("Could not find another host or HA group named %q to move accounts to", host.MoveAccountsTo.Value),
+		)
+		return diags
+	}
+	for _, account := range assigned {
+		accountName := account.Name
+		tflog.SubsystemInfo(withLogSubsystem(ctx, apiLogSubsystem), apiLogSubsystem, "moving account", map[string]interface{}{
+			"account": accountName,
+			"host":    host.MoveAccountsTo.Value,
+		})
+		_, _, err = r.p.client.DefaultApi.UpdateAccountHost(ctx, accountName, target.Id).Execute()
+		if err != nil {
+			diags.AddError(
+				"Error moving account",
+				"Could not move account "+accountName+": "+err.Error(),
+			)
+			return diags
+		}
+	}
+	return diags
+}
+
+// setHostStatus fills the health attributes of the host from its record on the main server
+func (r resourceHost) setHostStatus(ctx context.Context, host *client.Host, result *Host) error {
+	result.Status = types.String{Null: true}
+	if len(host.Status) > 0 {
+		result.Status = types.String{Value: host.Status}
+	}
+	result.LastHeartbeat = types.String{Null: true}
+	if heartbeat := host.Heartbeat(); len(heartbeat) > 0 {
+		result.LastHeartbeat = types.String{Value: heartbeat}
+	}
+
+	// accounts are assigned to the host's group, a standalone host has a group of its own
+	accounts, err := r.p.api.ListAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	result.Accounts = types.Set{ElemType: types.StringType, Elems: []attr.Value{}}
+	for _, account := range accounts {
+		if len(host.HostGroupId) > 0 && account.HostGroupId == host.HostGroupId && len(account.DisplayName) > 0 {
+			result.Accounts.Elems = append(result.Accounts.Elems, types.String{Value: account.DisplayName})
+		}
+	}
+	return nil
+}
+
+// checkService connects to the host and returns the state of the demisto service. An unreachable host is reported
+// as a warning, so a plan can still be made.
+func (r resourceHost) checkService(ctx context.Context, host Host, diags *diag.Diagnostics) types.String {
+	conn, _, connDiags := connectSSH(ctx, host.sshTarget(), host.ObservedHostKey.Value)
+	if connDiags.HasError() {
+		for _, d := range connDiags {
+			diags.AddWarning("Could not check demisto service: "+d.Summary(), d.Detail())
+		}
+		return types.String{Value: "unknown"}
+	}
+	defer conn.Close()
+	status := serviceStatus(ctx, conn)
+	if status != "active" {
+		diags.AddWarning(
+			"Demisto service is not active",
+			fmt.Sprintf("The demisto service on %s is %s", host.Name.Value, status),
+		)
+	}
+	return types.String{Value: status}
+}
+
+// serviceStatus returns the state of the demisto service as reported by systemctl, e.g. active or failed
+func serviceStatus(ctx context.Context, conn *sshConnection) string {
+	err := runSSHCommand(ctx, conn, "service check", "systemctl is-active demisto")
+	if err == nil {
+		return "active"
+	}
+	var cmdErr *sshCommandError
+	if errors.As(err, &cmdErr) && len(cmdErr.Tail) > 0 {
+		return strings.TrimSpace(cmdErr.Tail[len(cmdErr.Tail)-1])
+	}
+	return "unknown"
+}
+
+// upgrade runs the installer currently provided by the main server over the existing installation, then waits for
+// the host to report the target version. It returns the version reported by the host.
+func (r resourceHost) upgrade(ctx context.Context, host Host, observedHostKey string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// 1) connect to host server over ssh
+	conn, _, connDiags := connectSSH(ctx, host.sshTarget(), observedHostKey)
+	diags.Append(connDiags...)
+	if diags.HasError() {
+		return "", diags
+	}
+	defer conn.Close()
+
+	// 2) query main server with /host/build
+	haGroupId, buildDiags := r.buildInstaller(ctx, host.HAGroupName.Value)
+	diags.Append(buildDiags...)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	// 3) transfer installer to the host
+	err := r.transferInstaller(ctx, conn, host.InstallerTransfer.Value, haGroupId)
+	if err != nil {
+		diags.AddError(
+			"Error downloading installer",
+			"Could not download installer: "+err.Error(),
+		)
+		return "", diags
+	}
+
+	// 4) Take the install lock on the shared volume
+	installLock, err := installLockFor(ctx, conn, host)
+	if err != nil {
+		diags.AddError(
+			"Error acquiring install lock",
+			"Could not acquire install lock: "+err.Error(),
+		)
+		return "", diags
+	}
+	if installLock != nil {
+		defer func() {
+			if lockErr := installLock.Release(ctx); lockErr != nil {
+				diags.AddError(
+					"Error releasing install lock",
+					"Could not release install lock: "+lockErr.Error(),
+				)
+			}
+		}()
+	}
+
+	// 5) Execute installer, an existing installation is upgraded in place and keeps its data
+	log.Println("Executing upgrade")
+	diags.Append(runInstaller(ctx, conn, host, "upgrade")...)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	// Wait for the host to report the new version
+	timeout := 300 * time.Second
+	if !host.InstallationTimeout.Null {
+		timeout = time.Duration(host.InstallationTimeout.Value) * time.Second
+	}
+	var version string
+	err = resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		record, getErr := r.p.api.FindHost(ctx, host.Name.Value)
+		if getErr != nil {
+			return resource.RetryableError(getErr)
+		}
+		if record == nil {
+			return resource.RetryableError(fmt.Errorf("host %s is not registered", host.Name.Value))
+		}
+		version = record.ReportedVersion()
+		if !versionMatches(version, host.TargetVersion.Value) {
+			return resource.RetryableError(fmt.Errorf("host reports version %q", version))
+		}
+		return nil
+	})
+	if err != nil {
+		diags.AddError(
+			"Error upgrading host",
+			fmt.Sprintf("Host did not report version %s before timeout: %s", host.TargetVersion.Value, err.Error()),
+		)
+		return "", diags
+	}
+	return version, diags
+}
+
+// installLockFor takes the install lock on the host's NFS mount. It returns nil when the host has no shared storage.
+func installLockFor(ctx context.Context, conn *sshConnection, host Host) (*hostInstallLock, error) {
+	if host.NFSMount.Null {
+		return nil, nil
+	}
+	lockTimeout := 1800 * time.Second
+	if !host.LockTimeout.Null && host.LockTimeout.Value > 0 {
+		lockTimeout = time.Duration(host.LockTimeout.Value) * time.Second
+	}
+	lockTTL := 3600 * time.Second
+	if !host.LockTTL.Null && host.LockTTL.Value > 0 {
+		lockTTL = time.Duration(host.LockTTL.Value) * time.Second
+	}
+	lockKey := host.HAGroupName.Value + ":" + host.NFSMount.Value
+	return acquireHostInstallLock(ctx, conn, lockKey, host.NFSMount.Value, host.Name.Value, lockTimeout, lockTTL)
+}
+
+// versionMatches reports whether version is target or a more specific release of it, so 6.8 matches 6.8.0
+func versionMatches(version string, target string) bool {
+	return version == target || strings.HasPrefix(version, target+".") || strings.HasPrefix(version, target+"-")
+}
+
+// buildInstaller asks the main server to build the installer for the HA group, or a standalone host installer when
+// no group is given. It returns the id of the HA group.
+func (r resourceHost) buildInstaller(ctx context.Context, haGroupName string) (string, diag.Diagnostics) {
+	var haGroupId string
+	var diags diag.Diagnostics
+	var err error
+	if len(haGroupName) > 0 {
+		var haGroup *client.HAGroup
+		log.Println("List ha groups")
+		haGroup, err = r.p.api.FindHAGroupByName(ctx, haGroupName)
+		if err != nil {
+			diags.AddError(
+				"Error listing HA groups",
+				"Could not list HA groups: "+err.Error(),
+			)
+			return "", diags
+		}
+		if haGroup == nil {
+			diags.AddError(
+				"Error getting HA group",
+				"Could not find HA group "+haGroupName,
+			)
+			return "", diags
+		}
+		haGroupId = haGroup.Id
+		err = buildInstallerWhenIdle(ctx, "Already building host for ha group", func() (*http.Response, error) {
+			_, httpResponse, err := r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
+			return httpResponse, err
+		})
+		if err != nil {
+			diags.AddError(
+				"Error creating HA installer",
+				"Could not create HA installer: "+err.Error(),
+			)
+			return "", diags
+		}
+	} else {
+		err = buildInstallerWhenIdle(ctx, "Already building host installer", func() (*http.Response, error) {
+			_, httpResponse, err := r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
+			return httpResponse, err
+		})
+		if err != nil {
+			diags.AddError(
+				"Error creating host installer",
+				"Could not create host installer: "+err.Error(),
+			)
+			return "", diags
+		}
+	}
+
+	return haGroupId, diags
+}
+
+// installerBuildTimeout is how long to wait for another installer build to finish
+const installerBuildTimeout = 30 * time.Minute
+
+// buildInstallerWhenIdle starts an installer build. The main server builds one installer at a time and answers with
+// busyMessage while another build is running, so the build is retried until it is accepted.
+func buildInstallerWhenIdle(ctx context.Context, busyMessage string, build func() (*http.Response, error)) error {
+	return resource.RetryContext(ctx, installerBuildTimeout, func() *resource.RetryError {
+		httpResponse, err := build()
+		if err == nil {
+			return nil
+		}
+		var body []byte
+		if httpResponse != nil {
+			body, _ = io.ReadAll(httpResponse.Body)
+		}
+		if bytes.Contains(body, []byte(busyMessage)) {
+			return resource.RetryableError(err)
+		}
+		return resource.NonRetryableError(err)
+	})
+}
+
+// sshTarget returns the SSH connection settings of the host
+func (h Host) sshTarget() sshTarget {
+	return sshTarget{
+		Address:        h.ServerUrl.Value,
+		User:           h.SSHUser.Value,
+		Key:            h.SSHKey.Value,
+		Passphrase:     h.SSHKeyPassphrase.Value,
+		Certificate:    h.SSHCertificate.Value,
+		Password:       h.SSHPassword.Value,
+		UseAgent:       h.SSHUseAgent.Value,
+		HostKey:        h.HostKey.Value,
+		KnownHostsFile: h.KnownHostsFile.Value,
+		Bastions:       h.Bastions,
+	}
+}
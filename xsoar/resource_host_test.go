@@ -3,12 +3,15 @@ package xsoar
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -19,7 +22,7 @@ func TestAccHost_basic(t *testing.T) {
 		PreCheck: func() { testAccHostResourcePreCheck(t) },
 		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
 			"xsoar": func() (tfprotov6.ProviderServer, error) {
-				return tfsdk.NewProtocol6Server(New()), nil
+				return providerserver.NewProtocol6(New()())(), nil
 			},
 		},
 		CheckDestroy: testAccCheckHostResourceDestroy(rName),
@@ -37,12 +40,129 @@ func TestAccHost_basic(t *testing.T) {
 					"server_url",
 					"ssh_user",
 					"ssh_key",
+					"observed_host_key",
 				},
 			},
 		},
 	})
 }
 
+func TestAccHost_deregisterOnly(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccHostResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6(New()())(), nil
+			},
+		},
+		CheckDestroy: testAccCheckHostResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHostResourceDeregisterOnly(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckHostResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_host."+rName, "destroy_mode", "deregister_only"),
+					resource.TestCheckResourceAttr("xsoar_host."+rName, "accounts_on_destroy", "ignore"),
+				),
+			},
+		},
+	})
+}
+
+// TestInstallerCommand checks the command line used both to install and to upgrade a host
+func TestInstallerCommand(t *testing.T) {
+	null := types.String{Null: true}
+	tests := map[string]struct {
+		host        Host
+		want        string
+		wantSecrets string
+	}{
+		"standalone": {
+			host: Host{Name: types.String{Value: "host1"}, HAGroupName: null, ElasticsearchUrl: null},
+			want: `sudo /tmp/installer.sh -- '-y' '-external-address=host1'`,
+		},
+		"ha group": {
+			host: Host{Name: types.String{Value: "host1"}, HAGroupName: types.String{Value: "group1"}, ElasticsearchUrl: null},
+			want: `sudo /tmp/installer.sh -- '-y' '-external-address=host1' '-temp-folder=/tmp/demisto' '-ha'`,
+		},
+		"elasticsearch": {
+			host: Host{
+				Name:                   types.String{Value: "host1"},
+				HAGroupName:            null,
+				ElasticsearchUrl:       types.String{Value: "https://es:9200"},
+				ElasticsearchUsername:  types.String{Value: "elastic"},
+				ElasticsearchPassword:  types.String{Value: "it's $secret"},
+				ElasticsearchApiKey:    null,
+				ElasticIndexPrefix:     types.String{Value: "xsoar_"},
+				ElasticsearchCACert:    null,
+				ElasticsearchVerifyTLS: types.Bool{Null: true},
+				InstallerOptions: []InstallerOptions{{
+					ExternalAddress: types.String{Value: "xsoar.example.com"},
+					TempFolder:      types.String{Value: "/data/tmp"},
+					Tools:           types.Bool{Null: true},
+					Docker:          types.Bool{Null: true},
+					ExtraFlags:      types.List{ElemType: types.StringType, Null: true},
+				}},
+				ExtraFlags: types.List{ElemType: types.StringType, Null: true},
+			},
+			want: `sudo sh -c '. "$0" && rm -f "$0" && exec /tmp/installer.sh -- "$@"' '/tmp/xsoar_installer_1' '-y' ` +
+				`'-external-address=xsoar.example.com' '-elasticsearch-url=https://es:9200' '-elasticsearch-username=elastic' ` +
+				`'-elasticsearch-index-prefix=xsoar_' '-temp-folder=/data/tmp'; rc=$?; rm -f '/tmp/xsoar_installer_1'; [ $rc -eq 0 ]`,
+			wantSecrets: `set -- "$@" '-elasticsearch-password=it'\''s $secret'` + "\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.host.ExtraFlags.ElemType == nil {
+				test.host.ExtraFlags = types.List{ElemType: types.StringType, Null: true}
+			}
+			cmd, secrets, _, err := installerCommand(context.Background(), test.host, "/tmp/xsoar_installer_1")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if cmd != test.want {
+				t.Fatalf("unexpected command\n got: %s\nwant: %s", cmd, test.want)
+			}
+			if secrets != test.wantSecrets {
+				t.Fatalf("unexpected secrets\n got: %s\nwant: %s", secrets, test.wantSecrets)
+			}
+		})
+	}
+}
+
+// TestInstallerSecretsScript checks that the shell reads the secret arguments back unchanged
+func TestInstallerSecretsScript(t *testing.T) {
+	null := types.String{Null: true}
+	host := Host{
+		Name:                  types.String{Value: "host1"},
+		HAGroupName:           null,
+		ElasticsearchUrl:      types.String{Value: "https://es:9200"},
+		ElasticsearchUsername: null,
+		ElasticsearchPassword: types.String{Value: "it's $secret `x`"},
+		ElasticsearchApiKey:   types.String{Value: `a"b`},
+		ElasticIndexPrefix:    null,
+		ElasticsearchCACert:   null,
+		ExtraFlags:            types.List{ElemType: types.StringType, Null: true},
+	}
+	file := filepath.Join(t.TempDir(), "secrets")
+	_, secrets, _, err := installerCommand(context.Background(), host, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(secrets), 0600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("sh", "-c", `. "$0" && printf '%s\n' "$@"`, file, "-y").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "-y\n-elasticsearch-password=it's $secret `x`\n-elasticsearch-api-key=a\"b\n"
+	if string(out) != want {
+		t.Fatalf("unexpected arguments\n got: %s\nwant: %s", out, want)
+	}
+}
+
 func testAccHostResourcePreCheck(t *testing.T) {}
 
 func testAccCheckHostResourceExists(r string) resource.TestCheckFunc {
@@ -104,3 +224,21 @@ resource "xsoar_host" "{name}" {
 	c = strings.Replace(c, "{host}", host, -1)
 	return c
 }
+
+func testAccHostResourceDeregisterOnly(name string) string {
+	keyfile := os.Getenv("DEMISTO_HOST_KEYFILE")
+	host := os.Getenv("DEMISTO_HOST")
+	c := `
+resource "xsoar_host" "{name}" {
+  name                = "{host}"
+  server_url          = "{host}:22"
+  ssh_user            = "vagrant"
+  ssh_key             = file("{keyfile}")
+  destroy_mode        = "deregister_only"
+  accounts_on_destroy = "ignore"
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	c = strings.Replace(c, "{keyfile}", keyfile, -1)
+	c = strings.Replace(c, "{host}", host, -1)
+	return c
+}
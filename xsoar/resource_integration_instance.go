@@ -2,17 +2,13 @@ package xsoar
 
 import (
 	"context"
-	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"io"
 	"log"
-	"net/http"
 	"strings"
-	"time"
+	"terraform-provider-xsoar/internal/client"
 )
 
 type resourceIntegrationInstanceType struct{}
@@ -94,9 +90,20 @@ func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.Creat
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(plan.Account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.IncomingMapperId.Value != "" {
+		resp.Diagnostics.Append(r.p.requireServer("incoming_mapper_id", incomingMapperMinVersion, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Create
-	// list integrations
-	integrations, _, err := r.p.client.DefaultApi.ListIntegrations(ctx).Execute()
+	// find the integration the instance is created from
+	integration, err := r.p.api.FindIntegrationByBrand(ctx, plan.IntegrationName.Value)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error listing integration",
@@ -104,65 +111,64 @@ func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.Creat
 		)
 		return
 	}
-	var moduleConfiguration []interface{}
+	if integration == nil {
+		resp.Diagnostics.AddError(
+			"Error finding integration",
+			"Could not find integration "+plan.IntegrationName.Value,
+		)
+		return
+	}
+	moduleConfiguration := integration.Configuration
 	var moduleInstance = make(map[string]interface{})
-	configurations := integrations["configurations"].([]interface{})
-	for _, configuration := range configurations {
-		config := configuration.(map[string]interface{})
-		if config["name"].(string) == plan.IntegrationName.Value {
-			moduleConfiguration = config["configuration"].([]interface{})
-			moduleInstance["brand"] = config["name"].(string)
-			moduleInstance["canSample"] = config["canGetSamples"].(bool)
-			moduleInstance["category"] = config["category"].(string)
-			moduleInstance["configuration"] = configuration
-			moduleInstance["data"] = make([]map[string]interface{}, 0)
-			moduleInstance["defaultIgnore"] = false
-			moduleInstance["enabled"] = "true"
-			// todo: add this as a config option
-			//moduleInstance["engine"] = ""
-			//moduleInstance["engineGroup"] = ""
-			//moduleInstance["id"] = ""
-			var IncomingMapperId string
-			if ok := plan.IncomingMapperId.Value; ok != "" {
-				IncomingMapperId = plan.IncomingMapperId.Value
-			} else {
-				IncomingMapperId = ""
-			}
-			moduleInstance["incomingMapperId"] = IncomingMapperId
-			var MappingId string
-			if ok := plan.MappingId.Value; ok != "" {
-				MappingId = plan.MappingId.Value
-			} else {
-				MappingId = ""
-			}
-			moduleInstance["mappingId"] = MappingId
-			//moduleInstance["integrationLogLevel"] = ""
-			// todo: add this as a config option (byoi)
-			var isIntegrationScript bool
-			if val, ok := config["integrationScript"]; ok && val != nil {
-				isIntegrationScript = true
-			}
-			moduleInstance["isIntegrationScript"] = isIntegrationScript
-			//moduleInstance["isLongRunning"] = false
-			//moduleInstance["mappingId"] = ""
-			moduleInstance["name"] = plan.Name.Value
-			//moduleInstance["outgoingMapperId"] = ""
-			//moduleInstance["passwordProtected"] = false
-			var propLabels []string
-			plan.PropagationLabels.ElementsAs(ctx, propLabels, false)
-			moduleInstance["propagationLabels"] = propLabels
-			//moduleInstance["resetContext"] = false
-			moduleInstance["version"] = -1
-			break
-		}
+	moduleInstance["brand"] = integration.Name
+	moduleInstance["canSample"] = integration.CanGetSamples
+	moduleInstance["category"] = integration.Category
+	moduleInstance["configuration"] = integration.Record
+	moduleInstance["data"] = make([]map[string]interface{}, 0)
+	moduleInstance["defaultIgnore"] = false
+	moduleInstance["enabled"] = "true"
+	// todo: add this as a config option
+	//moduleInstance["engine"] = ""
+	//moduleInstance["engineGroup"] = ""
+	//moduleInstance["id"] = ""
+	var IncomingMapperId string
+	if ok := plan.IncomingMapperId.Value; ok != "" {
+		IncomingMapperId = plan.IncomingMapperId.Value
+	} else {
+		IncomingMapperId = ""
+	}
+	moduleInstance["incomingMapperId"] = IncomingMapperId
+	var MappingId string
+	if ok := plan.MappingId.Value; ok != "" {
+		MappingId = plan.MappingId.Value
+	} else {
+		MappingId = ""
+	}
+	moduleInstance["mappingId"] = MappingId
+	//moduleInstance["integrationLogLevel"] = ""
+	// todo: add this as a config option (byoi)
+	moduleInstance["isIntegrationScript"] = integration.IntegrationScript != nil
+	//moduleInstance["isLongRunning"] = false
+	//moduleInstance["mappingId"] = ""
+	moduleInstance["name"] = plan.Name.Value
+	//moduleInstance["outgoingMapperId"] = ""
+	//moduleInstance["passwordProtected"] = false
+	var propLabels []string
+	plan.PropagationLabels.ElementsAs(ctx, propLabels, false)
+	moduleInstance["propagationLabels"] = propLabels
+	//moduleInstance["resetContext"] = false
+	// version -1 overwrites whatever is saved, older servers reject it and create a new instance without a version
+	if r.p.serverAtLeast(instanceOverwriteMinVersion) {
+		moduleInstance["version"] = -1
 	}
 	var configs map[string]string
 	plan.Config.ElementsAs(ctx, &configs, true)
-	for _, parameter := range moduleConfiguration {
-		param := parameter.(map[string]interface{})
+	for _, param := range moduleConfiguration {
 		param["hasvalue"] = false
 		for configName, configValue := range configs {
-			if param["display"].(string) == configName || param["name"].(string) == configName {
+			display, _ := param["display"].(string)
+			paramName, _ := param["name"].(string)
+			if display == configName || paramName == configName {
 				param["value"] = configValue
 				param["hasvalue"] = true
 				break
@@ -171,27 +177,12 @@ func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.Creat
 		moduleInstance["data"] = append(moduleInstance["data"].([]map[string]interface{}), param)
 	}
 
-	var integration map[string]interface{}
-	var httpResponse *http.Response
-	var body []byte
-	err = resource.RetryContext(ctx, 10*time.Minute, func() *resource.RetryError {
-		if plan.Account.Null || len(plan.Account.Value) == 0 {
-			integration, httpResponse, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstance(ctx).CreateIntegrationRequest(moduleInstance).Execute()
-		} else {
-			integration, httpResponse, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstanceAccount(ctx, "acc_"+plan.Account.Value).CreateIntegrationRequest(moduleInstance).Execute()
-		}
-		if httpResponse != nil {
-			body, _ = io.ReadAll(httpResponse.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body))
-		}
-		if err != nil {
-			log.Println(err.Error())
-			time.Sleep(60 * time.Second)
-			return resource.RetryableError(fmt.Errorf("error message: %s, http response: %s", err, body))
-		}
-
-		return nil
-	})
+	var record map[string]interface{}
+	if plan.Account.Null || len(plan.Account.Value) == 0 {
+		record, _, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstance(ctx).CreateIntegrationRequest(moduleInstance).Execute()
+	} else {
+		record, _, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstanceAccount(ctx, "acc_"+plan.Account.Value).CreateIntegrationRequest(moduleInstance).Execute()
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error creating integration instance",
@@ -200,41 +191,17 @@ func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.Creat
 		return
 	}
 
-	var propagationLabels []attr.Value
-	if integration["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, prop := range integration["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   prop.(string),
-			})
-		}
+	instance, err := client.DecodeIntegrationInstance(record)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating integration instance",
+			"Could not create integration instance: "+err.Error(),
+		)
+		return
 	}
 
 	// Map response body to resource schema attribute
-	result := IntegrationInstance{
-		Name:              types.String{Value: integration["name"].(string)},
-		Id:                types.String{Value: integration["id"].(string)},
-		IntegrationName:   types.String{Value: integration["brand"].(string)},
-		Account:           plan.Account,
-		PropagationLabels: types.Set{Elems: propagationLabels, ElemType: types.StringType},
-		Config:            plan.Config,
-	}
-
-	IncomingMapperId, ok := integration["incomingMapperId"].(string)
-	if ok {
-		result.IncomingMapperId = types.String{Value: IncomingMapperId}
-	} else {
-		result.IncomingMapperId = types.String{Null: true}
-	}
-	MappingId, ok := integration["mappingId"].(string)
-	if ok {
-		result.MappingId = types.String{Value: MappingId}
-	} else {
-		result.MappingId = types.String{Null: true}
-	}
+	result := integrationInstanceModel(instance, plan.Account, plan.Config)
 
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
@@ -255,21 +222,15 @@ func (r resourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadRes
 	}
 
 	// Get resource from API
-	var integration map[string]interface{}
-	var httpResponse *http.Response
+	var integration *client.IntegrationInstance
 	var err error
 	if state.Account.Null || len(state.Account.Value) == 0 {
-		integration, httpResponse, err = r.p.client.DefaultApi.GetIntegrationInstance(ctx).SetIdentifier(state.Id.Value).Execute()
+		integration, err = r.p.api.FindIntegrationInstance(ctx, "", state.Id.Value)
 	} else {
-		var account map[string]interface{}
-		account, httpResponse, err = r.p.client.DefaultApi.GetAccount(ctx, "acc_"+state.Account.Value).Execute()
+		var account *client.Account
+		account, err = r.p.api.FindAccount(ctx, state.Account.Value)
 		if err != nil {
 			log.Println(err.Error())
-			if httpResponse != nil {
-				body, _ := io.ReadAll(httpResponse.Body)
-				payload, _ := io.ReadAll(httpResponse.Request.Body)
-				log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-			}
 			resp.Diagnostics.AddError(
 				"Error getting integration instance",
 				"Could not verify account existence: "+err.Error(),
@@ -280,15 +241,10 @@ func (r resourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadRes
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		integration, httpResponse, err = r.p.client.DefaultApi.GetIntegrationInstanceAccount(ctx, "acc_"+state.Account.Value).SetIdentifier(state.Id.Value).Execute()
+		integration, err = r.p.api.FindIntegrationInstance(ctx, state.Account.Value, state.Id.Value)
 	}
 	if err != nil {
 		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
 		resp.Diagnostics.AddError(
 			"Error getting integration instance",
 			"Could not get integration instance: "+err.Error(),
@@ -301,41 +257,8 @@ func (r resourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadRes
 		return
 	}
 
-	var propagationLabels []attr.Value
-	if integration["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, prop := range integration["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   prop.(string),
-			})
-		}
-	}
-
 	// Map response body to resource schema attribute
-	result := IntegrationInstance{
-		Name:              types.String{Value: integration["name"].(string)},
-		Id:                types.String{Value: integration["id"].(string)},
-		IntegrationName:   types.String{Value: integration["brand"].(string)},
-		Account:           state.Account,
-		PropagationLabels: types.Set{Elems: propagationLabels, ElemType: types.StringType},
-		Config:            state.Config,
-	}
-
-	IncomingMapperId, ok := integration["incomingMapperId"].(string)
-	if ok {
-		result.IncomingMapperId = types.String{Value: IncomingMapperId}
-	} else {
-		result.IncomingMapperId = types.String{Null: true}
-	}
-	MappingId, ok := integration["mappingId"].(string)
-	if ok {
-		result.MappingId = types.String{Value: MappingId}
-	} else {
-		result.MappingId = types.String{Null: true}
-	}
+	result := integrationInstanceModel(integration, state.Account, state.Config)
 
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
@@ -355,6 +278,17 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(plan.Account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.IncomingMapperId.Value != "" {
+		resp.Diagnostics.Append(r.p.requireServer("incoming_mapper_id", incomingMapperMinVersion, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Get current state
 	var state IntegrationInstance
 	diags = req.State.Get(ctx, &state)
@@ -364,8 +298,8 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 	}
 
 	// Build request
-	// list integrations
-	integrations, _, err := r.p.client.DefaultApi.ListIntegrations(ctx).Execute()
+	// find the integration the instance is created from
+	integration, err := r.p.api.FindIntegrationByBrand(ctx, plan.IntegrationName.Value)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error listing integration",
@@ -373,87 +307,97 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 		)
 		return
 	}
-	var moduleConfiguration []interface{}
+	if integration == nil {
+		resp.Diagnostics.AddError(
+			"Error finding integration",
+			"Could not find integration "+plan.IntegrationName.Value,
+		)
+		return
+	}
+	moduleConfiguration := integration.Configuration
 	var moduleInstance = make(map[string]interface{})
-	configurations := integrations["configurations"].([]interface{})
-	for _, configuration := range configurations {
-		config := configuration.(map[string]interface{})
-		if config["name"].(string) == plan.IntegrationName.Value {
-			moduleConfiguration = config["configuration"].([]interface{})
-			moduleInstance["brand"] = config["name"].(string)
-			moduleInstance["canSample"] = config["canGetSamples"].(bool)
-			moduleInstance["category"] = config["category"].(string)
-			moduleInstance["configuration"] = configuration
-			moduleInstance["data"] = make([]map[string]interface{}, 0)
-			moduleInstance["defaultIgnore"] = false
-			moduleInstance["enabled"] = "true"
-			// todo: add this as a config option
-			//moduleInstance["engine"] = ""
-			//moduleInstance["engineGroup"] = ""
-			moduleInstance["id"] = state.Id.Value
-			var IncomingMapperId string
-			if ok := plan.IncomingMapperId.Value; ok != "" {
-				IncomingMapperId = plan.IncomingMapperId.Value
-			} else {
-				IncomingMapperId = ""
-			}
-			moduleInstance["incomingMapperId"] = IncomingMapperId
-			var MappingId string
-			if ok := plan.MappingId.Value; ok != "" {
-				MappingId = plan.MappingId.Value
-			} else {
-				MappingId = ""
-			}
-			moduleInstance["mappingId"] = MappingId
-			//moduleInstance["integrationLogLevel"] = ""
-			// todo: add this as a config option (byoi)
-			var isIntegrationScript bool
-			if val, ok := config["integrationScript"]; ok && val != nil {
-				isIntegrationScript = true
-			}
-			moduleInstance["isIntegrationScript"] = isIntegrationScript
-			//moduleInstance["isLongRunning"] = false
-			//moduleInstance["mappingId"] = ""
-			moduleInstance["name"] = plan.Name.Value
-			//moduleInstance["outgoingMapperId"] = ""
-			//moduleInstance["passwordProtected"] = false
-			var propLabels []string
-			plan.PropagationLabels.ElementsAs(ctx, propLabels, false)
-			moduleInstance["propagationLabels"] = propLabels
-			//moduleInstance["resetContext"] = false
-			moduleInstance["version"] = -1
-			break
+	moduleInstance["brand"] = integration.Name
+	moduleInstance["canSample"] = integration.CanGetSamples
+	moduleInstance["category"] = integration.Category
+	moduleInstance["configuration"] = integration.Record
+	moduleInstance["data"] = make([]map[string]interface{}, 0)
+	moduleInstance["defaultIgnore"] = false
+	moduleInstance["enabled"] = "true"
+	// todo: add this as a config option
+	//moduleInstance["engine"] = ""
+	//moduleInstance["engineGroup"] = ""
+	moduleInstance["id"] = state.Id.Value
+	var IncomingMapperId string
+	if ok := plan.IncomingMapperId.Value; ok != "" {
+		IncomingMapperId = plan.IncomingMapperId.Value
+	} else {
+		IncomingMapperId = ""
+	}
+	moduleInstance["incomingMapperId"] = IncomingMapperId
+	var MappingId string
+	if ok := plan.MappingId.Value; ok != "" {
+		MappingId = plan.MappingId.Value
+	} else {
+		MappingId = ""
+	}
+	moduleInstance["mappingId"] = MappingId
+	//moduleInstance["integrationLogLevel"] = ""
+	// todo: add this as a config option (byoi)
+	moduleInstance["isIntegrationScript"] = integration.IntegrationScript != nil
+	//moduleInstance["isLongRunning"] = false
+	//moduleInstance["mappingId"] = ""
+	moduleInstance["name"] = plan.Name.Value
+	//moduleInstance["outgoingMapperId"] = ""
+	//moduleInstance["passwordProtected"] = false
+	var propLabels []string
+	plan.PropagationLabels.ElementsAs(ctx, propLabels, false)
+	moduleInstance["propagationLabels"] = propLabels
+	//moduleInstance["resetContext"] = false
+	// version -1 overwrites whatever is saved, older servers need the version the update is based on
+	if r.p.serverAtLeast(instanceOverwriteMinVersion) {
+		moduleInstance["version"] = -1
+	} else {
+		current, err := r.p.api.FindIntegrationInstance(ctx, state.Account.Value, state.Id.Value)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error getting integration instance",
+				"Could not get integration instance: "+err.Error(),
+			)
+			return
+		}
+		if current == nil {
+			resp.Diagnostics.AddError(
+				"Error updating integration instance",
+				"Could not find integration instance "+state.Name.Value,
+			)
+			return
 		}
+		moduleInstance["version"] = current.Version
 	}
-	for _, parameter := range moduleConfiguration {
-		param := parameter.(map[string]interface{})
+	for _, param := range moduleConfiguration {
 		param["hasvalue"] = false
 		for configName, configValue := range plan.Config.Elems {
-			if param["display"].(string) == configName || param["name"].(string) == configName {
+			display, _ := param["display"].(string)
+			paramName, _ := param["name"].(string)
+			if display == configName || paramName == configName {
 				param["value"], _ = configValue.ToTerraformValue(ctx)
 				param["hasvalue"] = true
 				break
 			}
 		}
 		if !param["hasvalue"].(bool) {
-			param["value"] = param["defaultValue"].(string)
+			param["value"], _ = param["defaultValue"].(string)
 		}
 		moduleInstance["data"] = append(moduleInstance["data"].([]map[string]interface{}), param)
 	}
-	var integration map[string]interface{}
-	var httpResponse *http.Response
+	var record map[string]interface{}
 	if state.Account.Null || len(state.Account.Value) == 0 {
-		integration, httpResponse, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstance(ctx).CreateIntegrationRequest(moduleInstance).Execute()
+		record, _, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstance(ctx).CreateIntegrationRequest(moduleInstance).Execute()
 	} else {
-		integration, httpResponse, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstanceAccount(ctx, "acc_"+plan.Account.Value).CreateIntegrationRequest(moduleInstance).Execute()
+		record, _, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstanceAccount(ctx, "acc_"+plan.Account.Value).CreateIntegrationRequest(moduleInstance).Execute()
 	}
 	if err != nil {
 		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
 		resp.Diagnostics.AddError(
 			"Error updating integration instance",
 			"Could not update integration instance: "+err.Error(),
@@ -461,41 +405,17 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 		return
 	}
 
-	var propagationLabels []attr.Value
-	if integration["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, prop := range integration["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   prop.(string),
-			})
-		}
+	instance, err := client.DecodeIntegrationInstance(record)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating integration instance",
+			"Could not update integration instance: "+err.Error(),
+		)
+		return
 	}
 
 	// Map response body to resource schema attribute
-	result := IntegrationInstance{
-		Name:              types.String{Value: integration["name"].(string)},
-		Id:                types.String{Value: integration["id"].(string)},
-		IntegrationName:   types.String{Value: integration["brand"].(string)},
-		Account:           plan.Account,
-		PropagationLabels: types.Set{Elems: propagationLabels, ElemType: types.StringType},
-		Config:            plan.Config,
-	}
-
-	IncomingMapperId, ok := integration["incomingMapperId"].(string)
-	if ok {
-		result.IncomingMapperId = types.String{Value: IncomingMapperId}
-	} else {
-		result.IncomingMapperId = types.String{Null: true}
-	}
-	MappingId, ok := integration["mappingId"].(string)
-	if ok {
-		result.MappingId = types.String{Value: MappingId}
-	} else {
-		result.MappingId = types.String{Null: true}
-	}
+	result := integrationInstanceModel(instance, plan.Account, plan.Config)
 
 	// Set state
 	diags = resp.State.Set(ctx, result)
@@ -538,15 +458,12 @@ func (r resourceIntegrationInstance) ImportState(ctx context.Context, req tfsdk.
 	var diags diag.Diagnostics
 	accname := strings.Split(req.ID, ".")
 	var acc, name string
-	var integration map[string]interface{}
-	var err error
 	if len(accname) == 1 {
 		name = req.ID
-		integration, _, err = r.p.client.DefaultApi.GetIntegrationInstance(ctx).SetIdentifier(name).Execute()
 	} else {
 		acc, name = accname[0], accname[1]
-		integration, _, err = r.p.client.DefaultApi.GetIntegrationInstanceAccount(ctx, "acc_"+acc).SetIdentifier(name).Execute()
 	}
+	integration, err := r.p.api.FindIntegrationInstance(ctx, acc, name)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting integration instance",
@@ -562,45 +479,11 @@ func (r resourceIntegrationInstance) ImportState(ctx context.Context, req tfsdk.
 		return
 	}
 
-	var propagationLabels []attr.Value
-	if integration["propagationLabels"] == nil {
-		propagationLabels = []attr.Value{}
-	} else {
-		for _, prop := range integration["propagationLabels"].([]interface{}) {
-			propagationLabels = append(propagationLabels, types.String{
-				Unknown: false,
-				Null:    false,
-				Value:   prop.(string),
-			})
-		}
-	}
-
 	// Map response body to resource schema attribute
-	result := IntegrationInstance{
-		Name:              types.String{Value: integration["name"].(string)},
-		Id:                types.String{Value: integration["id"].(string)},
-		IntegrationName:   types.String{Value: integration["brand"].(string)},
-		PropagationLabels: types.Set{Elems: propagationLabels, ElemType: types.StringType},
-		Config:            types.Map{},
-	}
-
-	IncomingMapperId, ok := integration["incomingMapperId"].(string)
-	if ok {
-		result.IncomingMapperId = types.String{Value: IncomingMapperId}
-	} else {
-		result.IncomingMapperId = types.String{Null: true}
-	}
-	MappingId, ok := integration["mappingId"].(string)
-	if ok {
-		result.MappingId = types.String{Value: MappingId}
-	} else {
-		result.MappingId = types.String{Null: true}
-	}
+	result := integrationInstanceModel(integration, types.String{Null: true}, types.Map{})
 
 	if acc != "" {
 		result.Account = types.String{Value: acc}
-	} else {
-		result.Account = types.String{Null: true}
 	}
 
 	// Generate resource state struct
@@ -610,3 +493,29 @@ func (r resourceIntegrationInstance) ImportState(ctx context.Context, req tfsdk.
 		return
 	}
 }
+
+// integrationInstanceModel maps an integration instance to the resource schema. The configuration is not returned
+// by the server, so it is taken from the plan or state along with the account.
+func integrationInstanceModel(instance *client.IntegrationInstance, account types.String, config types.Map) IntegrationInstance {
+	propagationLabels := []attr.Value{}
+	for _, label := range instance.PropagationLabels {
+		propagationLabels = append(propagationLabels, types.String{Value: label})
+	}
+	result := IntegrationInstance{
+		Name:              types.String{Value: instance.Name},
+		Id:                types.String{Value: instance.Id},
+		IntegrationName:   types.String{Value: instance.Brand},
+		Account:           account,
+		PropagationLabels: types.Set{Elems: propagationLabels, ElemType: types.StringType},
+		Config:            config,
+		IncomingMapperId:  types.String{Null: true},
+		MappingId:         types.String{Null: true},
+	}
+	if instance.IncomingMapperId != nil {
+		result.IncomingMapperId = types.String{Value: *instance.IncomingMapperId}
+	}
+	if instance.MappingId != nil {
+		result.MappingId = types.String{Value: *instance.MappingId}
+	}
+	return result
+}
@@ -0,0 +1,224 @@
+package xsoar
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+type resourceMainHostType struct{}
+
+// GetSchema Resource schema
+func (r resourceMainHostType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"url": {
+				Type:          types.StringType,
+				Required:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"id": {
+				Type:          types.StringType,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.UseStateForUnknown()),
+			},
+			"admin_username": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"initial_password": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"admin_password": {
+				Type:      types.StringType,
+				Required:  true,
+				Sensitive: true,
+			},
+			"api_key": {
+				Type:      types.StringType,
+				Required:  true,
+				Sensitive: true,
+			},
+			"api_key_name": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"insecure": {
+				Type:     types.BoolType,
+				Optional: true,
+			},
+			"health_timeout": {
+				Type:     types.Int64Type,
+				Optional: true,
+			},
+		},
+	}, nil
+}
+
+// NewResource instance
+func (r resourceMainHostType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceMainHost{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+// resourceMainHost talks to the main server directly rather than through the provider's client, since the API key the
+// client uses only exists once this resource has been created
+type resourceMainHost struct {
+	p provider
+}
+
+// ModifyPlan refuses to change admin_password once the main host is bootstrapped. The password is only set on the first
+// login, so a new value would be stored in state without ever reaching the server.
+func (r resourceMainHost) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan MainHost
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	var state MainHost
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.AdminPassword.Unknown && !plan.AdminPassword.Equal(state.AdminPassword) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("admin_password"),
+			"Cannot change admin_password",
+			"admin_password is only set when "+state.Url.Value+" is bootstrapped and cannot be changed by the provider. "+
+				"Change the password on the server, then remove the resource from state with terraform state rm so the "+
+				"next apply records the new value.",
+		)
+	}
+}
+
+// Create a new resource
+func (r resourceMainHost) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	// Retrieve values from plan
+	var plan MainHost
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := bootstrapMainHost(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error bootstrapping main host",
+			"Could not bootstrap main host "+plan.Url.Value+": "+err.Error(),
+		)
+		return
+	}
+
+	result := plan
+	result.Id = types.String{Value: plan.Url.Value}
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourceMainHost) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	// Get current state
+	var state MainHost
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s, err := newMainHostSession(state.Url.Value, state.Insecure.Value)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading main host",
+			"Could not create session: "+err.Error(),
+		)
+		return
+	}
+	valid, err := s.apiKeyValid(ctx, state.ApiKey.Value)
+	if err != nil {
+		// an unreachable main server is not a reason to bootstrap it again
+		resp.Diagnostics.AddWarning(
+			"Main host unreachable",
+			"Could not check the API key on "+state.Url.Value+": "+err.Error(),
+		)
+		return
+	}
+	if !valid {
+		tflog.SubsystemInfo(withLogSubsystem(ctx, apiLogSubsystem), apiLogSubsystem, "API key is no longer accepted, removing main host from state", map[string]interface{}{
+			"url": state.Url.Value,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+}
+
+// Update resource
+func (r resourceMainHost) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	// Get plan values
+	var plan MainHost
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get current state
+	var state MainHost
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// creates the API key if it changed, the passwords are only used while bootstrapping, see ModifyPlan
+	err := bootstrapMainHost(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating main host",
+			"Could not update main host "+plan.Url.Value+": "+err.Error(),
+		)
+		return
+	}
+
+	result := plan
+	result.Id = state.Id
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourceMainHost) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	var state MainHost
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The main server, its password and the API key are left as they are
+	tflog.SubsystemInfo(withLogSubsystem(ctx, apiLogSubsystem), apiLogSubsystem, "removing main host from state, the server is not changed", map[string]interface{}{
+		"url": state.Url.Value,
+	})
+	resp.State.RemoveResource(ctx)
+}
@@ -0,0 +1,50 @@
+package xsoar
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"testing"
+)
+
+func TestMainHostAdminPasswordChange(t *testing.T) {
+	tests := map[string]struct {
+		password types.String
+		wantErr  bool
+	}{
+		"unchanged": {password: types.String{Value: "secret1"}},
+		"changed":   {password: types.String{Value: "secret2"}, wantErr: true},
+		"unknown":   {password: types.String{Unknown: true}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			schema, _ := resourceMainHostType{}.GetSchema(ctx)
+			host := MainHost{
+				Url:             types.String{Value: "https://xsoar.example.com"},
+				Id:              types.String{Value: "https://xsoar.example.com"},
+				AdminUsername:   types.String{Null: true},
+				InitialPassword: types.String{Null: true},
+				AdminPassword:   types.String{Value: "secret1"},
+				ApiKey:          types.String{Value: "key"},
+				ApiKeyName:      types.String{Null: true},
+				Insecure:        types.Bool{Null: true},
+				HealthTimeout:   types.Int64{Null: true},
+			}
+			state := tfsdk.State{Schema: schema}
+			diags := state.Set(ctx, host)
+			host.AdminPassword = test.password
+			plan := tfsdk.Plan{Schema: schema}
+			diags.Append(plan.Set(ctx, host)...)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			resp := &tfsdk.ModifyResourcePlanResponse{Plan: plan}
+			resourceMainHost{}.ModifyPlan(ctx, tfsdk.ModifyResourcePlanRequest{State: state, Plan: plan}, resp)
+			if resp.Diagnostics.HasError() != test.wantErr {
+				t.Fatalf("expected error %t, got %v", test.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
@@ -9,9 +9,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
 	"log"
-	"net/http"
 	"strings"
 )
 
@@ -112,6 +110,12 @@ func (r resourceMapper) Create(ctx context.Context, req tfsdk.CreateResourceRequ
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_mapper", mapperMinVersion(plan.Direction.Value), false)...)
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(plan.Account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Create
 	mapperRequest := *openapi.NewCreateUpdateClassifierRequest()
 	mapperRequest.SetType("mapping-" + plan.Direction.Value)
@@ -135,19 +139,13 @@ func (r resourceMapper) Create(ctx context.Context, req tfsdk.CreateResourceRequ
 		mapperRequest.SetPropagationLabels(props)
 	}
 	var mapper openapi.InstanceClassifier
-	var httpResponse *http.Response
 	if plan.Account.Null || len(plan.Account.Value) == 0 {
-		mapper, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(mapperRequest).Execute()
+		mapper, _, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(mapperRequest).Execute()
 	} else {
-		mapper, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(mapperRequest).Execute()
+		mapper, _, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(mapperRequest).Execute()
 	}
 	if err != nil {
 		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
 		resp.Diagnostics.AddError(
 			"Error creating mapper",
 			"Could not create mapper: "+err.Error(),
@@ -202,12 +200,11 @@ func (r resourceMapper) Read(ctx context.Context, req tfsdk.ReadResourceRequest,
 
 	// Get resource from API
 	var mapper openapi.InstanceClassifier
-	var httpResponse *http.Response
 	var err error
 	if state.Account.Null || len(state.Account.Value) == 0 {
-		mapper, httpResponse, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(state.Name.Value).Execute()
+		mapper, _, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(state.Name.Value).Execute()
 	} else {
-		mapper, httpResponse, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+state.Account.Value).SetIdentifier(state.Name.Value).Execute()
+		mapper, _, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+state.Account.Value).SetIdentifier(state.Name.Value).Execute()
 	}
 	if err != nil {
 		// determine if the error is a not found error or not
@@ -218,11 +215,6 @@ func (r resourceMapper) Read(ctx context.Context, req tfsdk.ReadResourceRequest,
 			return
 		}
 		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
 		resp.Diagnostics.AddError(
 			"Error creating classifier",
 			"Could not create classifier: "+err.Error(),
@@ -274,6 +266,12 @@ func (r resourceMapper) Update(ctx context.Context, req tfsdk.UpdateResourceRequ
 		return
 	}
 
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_mapper", mapperMinVersion(plan.Direction.Value), false)...)
+	resp.Diagnostics.Append(r.p.requireServer("account", "", accountPathPrefix(plan.Account) != "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Get current state
 	var state Mapper
 	diags = req.State.Get(ctx, &state)
@@ -306,16 +304,10 @@ func (r resourceMapper) Update(ctx context.Context, req tfsdk.UpdateResourceRequ
 		mapperRequest.SetPropagationLabels(props)
 	}
 	var mapper openapi.InstanceClassifier
-	var httpResponse *http.Response
 	if plan.Account.Null || len(plan.Account.Value) == 0 {
-		mapper, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(mapperRequest).Execute()
+		mapper, _, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(mapperRequest).Execute()
 	} else {
-		mapper, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(mapperRequest).Execute()
-	}
-	if httpResponse != nil {
-		body, _ := io.ReadAll(httpResponse.Body)
-		payload, _ := io.ReadAll(httpResponse.Request.Body)
-		log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
+		mapper, _, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(mapperRequest).Execute()
 	}
 	if err != nil {
 		log.Println(err.Error())
@@ -373,19 +365,13 @@ func (r resourceMapper) Delete(ctx context.Context, req tfsdk.DeleteResourceRequ
 
 	// Delete
 	var err error
-	var httpResponse *http.Response
 	if state.Account.Null || len(state.Account.Value) == 0 {
-		httpResponse, err = r.p.client.DefaultApi.DeleteClassifier(ctx, state.Id.Value).Execute()
+		_, err = r.p.client.DefaultApi.DeleteClassifier(ctx, state.Id.Value).Execute()
 	} else {
-		httpResponse, err = r.p.client.DefaultApi.DeleteClassifierAccount(ctx, state.Id.Value, "acc_"+state.Account.Value).Execute()
+		_, err = r.p.client.DefaultApi.DeleteClassifierAccount(ctx, state.Id.Value, "acc_"+state.Account.Value).Execute()
 	}
 	if err != nil {
 		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
 		resp.Diagnostics.AddError(
 			"Error deleting mapper",
 			"Could not delete mapper: "+err.Error(),
@@ -398,6 +384,11 @@ func (r resourceMapper) Delete(ctx context.Context, req tfsdk.DeleteResourceRequ
 }
 
 func (r resourceMapper) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	resp.Diagnostics.Append(r.p.requireServer("xsoar_mapper", classifierMinVersion, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var diags diag.Diagnostics
 	accname := strings.Split(req.ID, ".")
 	var acc, name string
@@ -465,3 +456,11 @@ func (r resourceMapper) ImportState(ctx context.Context, req tfsdk.ImportResourc
 		return
 	}
 }
+
+// mapperMinVersion returns the version of the main server required by mappers of the given direction
+func mapperMinVersion(direction string) string {
+	if direction == "outgoing" {
+		return outgoingMapperMinVersion
+	}
+	return classifierMinVersion
+}
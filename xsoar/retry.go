@@ -0,0 +1,198 @@
+package xsoar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// retryConfig controls how often, and how long apart, a failed API call is retried
+type retryConfig struct {
+	MaxAttempts int
+	MinWait     time.Duration
+	MaxWait     time.Duration
+}
+
+var defaultRetryConfig = retryConfig{
+	MaxAttempts: 5,
+	MinWait:     time.Second,
+	MaxWait:     30 * time.Second,
+}
+
+// retryTransport retries requests the main server could not handle at the moment, see retryable. It waits with
+// exponential backoff and jitter between attempts, or as long as the server asks for with Retry-After up to MaxWait,
+// and stops when the request's context is done. Each attempt, including reading its response, is abandoned after
+// timeout when it is set, unless the request's context is marked with withoutRequestTimeout.
+type retryTransport struct {
+	next    http.RoundTripper
+	config  retryConfig
+	timeout time.Duration
+}
+
+// errRequestTimeout is returned when an attempt takes longer than request_timeout
+var errRequestTimeout = errors.New("request timed out")
+
+type noRequestTimeoutKey struct{}
+
+// withoutRequestTimeout returns a context whose requests are not limited by request_timeout, for downloads that
+// legitimately take longer than an API call
+func withoutRequestTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRequestTimeoutKey{}, true)
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := t.attempt(req)
+		if attempt >= t.config.MaxAttempts || !retryable(req, resp, err) {
+			return resp, err
+		}
+		// a request body can only be sent again if it can be recreated
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		ctx := withLogSubsystem(req.Context(), apiLogSubsystem)
+		fields := map[string]interface{}{
+			"method":       req.Method,
+			"url":          req.URL.Redacted(),
+			"wait":         wait.String(),
+			"next_attempt": attempt + 1,
+			"max_attempts": t.config.MaxAttempts,
+		}
+		if resp != nil {
+			fields["status"] = resp.StatusCode
+			tflog.SubsystemWarn(ctx, apiLogSubsystem, "retrying request", fields)
+			// drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			fields["error"] = err.Error()
+			tflog.SubsystemWarn(ctx, apiLogSubsystem, "retrying request", fields)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// attempt sends req once, limited to the request timeout
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 || req.Context().Value(noRequestTimeoutKey{}) != nil {
+		return t.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		// only the attempt timed out, the request itself may still be retried
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && req.Context().Err() == nil {
+			err = fmt.Errorf("%w after %s: %s", errRequestTimeout, t.timeout, err)
+		}
+		return nil, err
+	}
+	// the timeout stays in force until the body is closed
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the context of an attempt when its response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryable reports whether a failed round trip is worth another attempt. Status 429 and 503 mean the server turned
+// the request away, so any request is retried. After a 502, a 504 or a dropped connection the server may still have
+// handled the request, so only idempotent requests are retried; sending a POST again could create an account or an
+// instance twice. The same holds for an attempt that took longer than request_timeout.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		dropped := errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+		return (dropped || errors.Is(err, errRequestTimeout)) && idempotent(req.Method)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(req.Method)
+	}
+	return false
+}
+
+// idempotent reports whether sending a request with method more than once has the same effect as sending it once
+func idempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait before the next attempt. Retry-After is honoured when the server sends it, up to
+// MaxWait, otherwise the wait doubles with every attempt, up to MaxWait, and a random part is added so that parallel requests
+// do not retry in lockstep.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if after := resp.Header.Get("Retry-After"); after != "" {
+			if seconds, err := strconv.Atoi(after); err == nil && seconds >= 0 {
+				return t.capWait(time.Duration(seconds) * time.Second)
+			}
+			if date, err := http.ParseTime(after); err == nil {
+				if wait := time.Until(date); wait > 0 {
+					return t.capWait(wait)
+				}
+				return 0
+			}
+		}
+	}
+
+	wait := t.config.MaxWait
+	if attempt < 32 && t.config.MinWait<<(attempt-1) < wait {
+		wait = t.config.MinWait << (attempt - 1)
+	}
+	// wait a random time between half and all of the backoff
+	half := int64(wait / 2)
+	if half > 0 {
+		wait = time.Duration(half + rand.Int63n(half+1))
+	}
+	if wait < t.config.MinWait {
+		wait = t.config.MinWait
+	}
+	return wait
+}
+
+// capWait limits a wait the server asked for to MaxWait, so a misbehaving server cannot stall the provider
+func (t *retryTransport) capWait(wait time.Duration) time.Duration {
+	if wait > t.config.MaxWait {
+		return t.config.MaxWait
+	}
+	return wait
+}
@@ -0,0 +1,176 @@
+package xsoar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryable(t *testing.T) {
+	reset := fmt.Errorf("read: %w", syscall.ECONNRESET)
+	tests := []struct {
+		method string
+		status int
+		err    error
+		want   bool
+	}{
+		{http.MethodGet, http.StatusTooManyRequests, nil, true},
+		{http.MethodPost, http.StatusTooManyRequests, nil, true},
+		{http.MethodPost, http.StatusServiceUnavailable, nil, true},
+		{http.MethodGet, http.StatusBadGateway, nil, true},
+		{http.MethodDelete, http.StatusGatewayTimeout, nil, true},
+		{http.MethodPost, http.StatusBadGateway, nil, false},
+		{http.MethodPost, http.StatusGatewayTimeout, nil, false},
+		{http.MethodGet, http.StatusInternalServerError, nil, false},
+		{http.MethodGet, http.StatusBadRequest, nil, false},
+		{http.MethodGet, http.StatusOK, nil, false},
+		{http.MethodGet, 0, reset, true},
+		{http.MethodPut, 0, io.ErrUnexpectedEOF, true},
+		{http.MethodGet, 0, io.EOF, true},
+		{http.MethodPost, 0, reset, false},
+		{http.MethodPost, 0, io.EOF, false},
+		{http.MethodGet, 0, errors.New("x509: certificate signed by unknown authority"), false},
+		{http.MethodGet, 0, fmt.Errorf("%w after 1s", errRequestTimeout), true},
+		{http.MethodPost, 0, fmt.Errorf("%w after 1s", errRequestTimeout), false},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, "https://xsoar.example.com/accounts", nil)
+		var resp *http.Response
+		if test.err == nil {
+			resp = &http.Response{StatusCode: test.status, Header: http.Header{}}
+		}
+		if got := retryable(req, resp, test.err); got != test.want {
+			t.Errorf("retryable(%s, %d, %v) = %v, want %v", test.method, test.status, test.err, got, test.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	transport := &retryTransport{config: retryConfig{MaxAttempts: 10, MinWait: time.Second, MaxWait: 8 * time.Second}}
+	for attempt, ceiling := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		4:  8 * time.Second,
+		5:  8 * time.Second,
+		40: 8 * time.Second,
+	} {
+		for i := 0; i < 20; i++ {
+			wait := transport.backoff(attempt, nil)
+			floor := ceiling / 2
+			if floor < transport.config.MinWait {
+				floor = transport.config.MinWait
+			}
+			if wait < floor || wait > ceiling {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", attempt, wait, floor, ceiling)
+			}
+		}
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	transport := &retryTransport{config: retryConfig{MaxAttempts: 5, MinWait: time.Second, MaxWait: 30 * time.Second}}
+	tests := map[string]struct {
+		header string
+		want   time.Duration
+	}{
+		"seconds":          {"3", 3 * time.Second},
+		"zero":             {"0", 0},
+		"capped seconds":   {"3600", 30 * time.Second},
+		"capped date":      {time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), 30 * time.Second},
+		"date in the past": {time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{test.header}}}
+			if wait := transport.backoff(1, resp); wait != test.want {
+				t.Fatalf("backoff = %s, want %s", wait, test.want)
+			}
+		})
+	}
+}
+
+func TestRetryTransportDoesNotRepeatPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &retryTransport{
+		next:   http.DefaultTransport,
+		config: retryConfig{MaxAttempts: 3, MinWait: time.Millisecond, MaxWait: time.Millisecond},
+	}}
+
+	resp, err := client.Post(server.URL+"/account", "application/json", strings.NewReader(`{"name":"tenant1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if calls != 1 {
+		t.Fatalf("POST was sent %d times after a 502, want 1", calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	resp, err = client.Get(server.URL + "/accounts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if calls != 3 {
+		t.Fatalf("GET was sent %d times after a 502, want 3", calls)
+	}
+}
+
+func TestRetryTransportTimeoutPerAttempt(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// only the first attempt is slow
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-time.After(500 * time.Millisecond):
+			case <-r.Context().Done():
+			}
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &retryTransport{
+		next:    http.DefaultTransport,
+		config:  retryConfig{MaxAttempts: 3, MinWait: time.Millisecond, MaxWait: time.Millisecond},
+		timeout: 100 * time.Millisecond,
+	}}
+
+	resp, err := client.Get(server.URL + "/accounts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("unexpected body %q, %v", body, err)
+	}
+	if calls != 2 {
+		t.Fatalf("GET was sent %d times after a timeout, want 2", calls)
+	}
+
+	// downloads are not limited by the timeout
+	atomic.StoreInt32(&calls, 0)
+	req, _ := http.NewRequestWithContext(withoutRequestTimeout(context.Background()), http.MethodGet, server.URL+"/host/download", nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if calls != 1 {
+		t.Fatalf("download was sent %d times, want 1", calls)
+	}
+}
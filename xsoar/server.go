@@ -0,0 +1,143 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net/http"
+	"strconv"
+	"strings"
+	"terraform-provider-xsoar/internal/client"
+)
+
+// Versions required by features of the provider, and by payloads the server rejects before them
+const (
+	// multi-tenant accounts, HA groups and hosts as managed through the API of the SDK
+	multiTenantMinVersion = "6.0.0"
+	// accountRoles and syncOnCreation of a new account, older servers give it the default role and sync it later
+	accountCreateMinVersion = "6.2.0"
+	// classifiers of type classification and mappers of type mapping-incoming, which came with the classification and
+	// mapping of 6.0
+	classifierMinVersion = "6.0.0"
+	// mappers of type mapping-outgoing, used to mirror incidents out
+	outgoingMapperMinVersion = "6.1.0"
+	// the incoming mapper of an integration instance, set apart from its classifier
+	incomingMapperMinVersion = "6.0.0"
+	// saving an integration instance with version -1, which overwrites it whatever its current version
+	instanceOverwriteMinVersion = "6.1.0"
+	// engines and engine groups as managed through /engines
+	engineMinVersion = "6.1.0"
+	// API keys created with a value chosen by the client, as xsoar_api_key and xsoar_main_host create them
+	apiKeyMinVersion = "6.1.0"
+)
+
+// serverInfo describes the main server the provider is configured for. Fields are left empty when they could not be
+// detected, in which case nothing is gated on them and the server decides.
+type serverInfo struct {
+	// Version is the version as reported by the server, e.g. 6.8.0
+	Version string
+	// MultiTenant is nil when it is not known whether the server is a multi-tenant main server
+	MultiTenant *bool
+}
+
+// detectServer reads the version of the main server from /about, and whether it runs in multi-tenant mode by listing
+// its accounts, which only a multi-tenant main server serves. XSOAR 8 and XSIAM tenants have no multi-tenant mode.
+func detectServer(ctx context.Context, api *openapi.APIClient, xsoar8 bool) (*serverInfo, error) {
+	about, err := client.New(api).GetAbout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &serverInfo{Version: about.ServerVersion()}
+
+	multiTenant := false
+	if !xsoar8 {
+		httpResponse, err := sendRequest(ctx, api, http.MethodGet, "/accounts", nil)
+		switch {
+		case err == nil:
+			httpResponse.Body.Close()
+			multiTenant = true
+		case httpResponse != nil && httpResponse.StatusCode >= 400 && httpResponse.StatusCode < 500 &&
+			httpResponse.StatusCode != http.StatusUnauthorized && httpResponse.StatusCode != http.StatusTooManyRequests:
+			multiTenant = false
+		default:
+			// the server could not answer, so the mode stays unknown
+			tflog.Warn(ctx, "Could not detect whether the main server is multi-tenant", map[string]interface{}{"error": fmt.Sprint(err)})
+			return info, nil
+		}
+	}
+	info.MultiTenant = &multiTenant
+	return info, nil
+}
+
+// requireServer returns an error diagnostic when the main server is known not to support a feature: when its version
+// is older than minVersion, or when the feature needs a multi-tenant main server and it is not one. An empty
+// minVersion does not check the version.
+func (p provider) requireServer(feature string, minVersion string, multiTenant bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if p.server == nil {
+		return diags
+	}
+	if multiTenant && p.server.MultiTenant != nil && !*p.server.MultiTenant {
+		diags.AddError(
+			"Unsupported server",
+			feature+" is only supported by a multi-tenant main server, "+p.data.MainHost.Value+" is not one",
+		)
+		return diags
+	}
+	if err := checkServerVersion(feature, minVersion, p.server.Version); err != nil {
+		diags.AddError("Unsupported server version", err.Error())
+	}
+	return diags
+}
+
+// serverAtLeast reports whether the main server runs minVersion or later. A version that is not known is assumed to
+// be recent enough, so the server decides.
+func (p provider) serverAtLeast(minVersion string) bool {
+	return p.server == nil || checkServerVersion("", minVersion, p.server.Version) == nil
+}
+
+// checkServerVersion returns an error naming feature when version is older than minVersion. An empty or unparsable
+// version, or an empty minVersion, is not checked.
+func checkServerVersion(feature string, minVersion string, version string) error {
+	if minVersion == "" || version == "" {
+		return nil
+	}
+	parsed, ok := parseServerVersion(version)
+	required, _ := parseServerVersion(minVersion)
+	if ok && compareServerVersions(parsed, required) < 0 {
+		return fmt.Errorf("%s requires XSOAR >= %s, the main server runs %s", feature, minVersion, version)
+	}
+	return nil
+}
+
+// parseServerVersion parses a version such as 6.8.0 or 8.4.0-1234 into its major, minor and patch numbers
+func parseServerVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	version = strings.SplitN(version, "-", 2)[0]
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return parsed, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
+
+func compareServerVersions(a [3]int, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
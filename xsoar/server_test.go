@@ -0,0 +1,136 @@
+package xsoar
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+	"testing"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    [3]int
+		ok      bool
+	}{
+		{"6.8.0", [3]int{6, 8, 0}, true},
+		{"6.10.1", [3]int{6, 10, 1}, true},
+		{"8.4.0-1234567", [3]int{8, 4, 0}, true},
+		{"6.8", [3]int{6, 8, 0}, true},
+		{"6", [3]int{6, 0, 0}, true},
+		{"", [3]int{}, false},
+		{"master", [3]int{}, false},
+		{"6.x.0", [3]int{}, false},
+		{"6.8.0.1", [3]int{}, false},
+	}
+	for _, test := range tests {
+		got, ok := parseServerVersion(test.version)
+		if ok != test.ok || (ok && got != test.want) {
+			t.Errorf("parseServerVersion(%q) = %v, %v, want %v, %v", test.version, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestCheckServerVersion(t *testing.T) {
+	tests := []struct {
+		minVersion string
+		version    string
+		wantErr    bool
+	}{
+		{"6.1.0", "6.0.2", true},
+		{"6.1.0", "6.1.0", false},
+		{"6.1.0", "6.10.0", false},
+		{"6.2.0", "6.1.9-123", true},
+		{"6.1.0", "8.0.0", false},
+		// unknown or unparsable versions are left to the server
+		{"6.1.0", "", false},
+		{"6.1.0", "master", false},
+		{"", "5.5.0", false},
+	}
+	for _, test := range tests {
+		err := checkServerVersion("feature", test.minVersion, test.version)
+		if (err != nil) != test.wantErr {
+			t.Errorf("checkServerVersion(%q, %q) = %v, want error %v", test.minVersion, test.version, err, test.wantErr)
+		}
+	}
+}
+
+func TestRequireServer(t *testing.T) {
+	multiTenant := true
+	singleTenant := false
+	data := &providerData{MainHost: types.String{Value: "https://xsoar.example.com"}}
+	tests := map[string]struct {
+		server      *serverInfo
+		minVersion  string
+		multiTenant bool
+		wantErr     string
+	}{
+		"server not detected": {
+			server:      nil,
+			minVersion:  accountCreateMinVersion,
+			multiTenant: true,
+		},
+		"recent multi-tenant server": {
+			server:      &serverInfo{Version: "6.8.0", MultiTenant: &multiTenant},
+			minVersion:  multiTenantMinVersion,
+			multiTenant: true,
+		},
+		"old server": {
+			server:     &serverInfo{Version: "6.0.2", MultiTenant: &multiTenant},
+			minVersion: outgoingMapperMinVersion,
+			wantErr:    "requires XSOAR >= " + outgoingMapperMinVersion + ", the main server runs 6.0.2",
+		},
+		"single tenant server": {
+			server:      &serverInfo{Version: "6.8.0", MultiTenant: &singleTenant},
+			minVersion:  multiTenantMinVersion,
+			multiTenant: true,
+			wantErr:     "is only supported by a multi-tenant main server",
+		},
+		"single tenant server for a feature of every server": {
+			server:     &serverInfo{Version: "6.8.0", MultiTenant: &singleTenant},
+			minVersion: engineMinVersion,
+		},
+		"unknown mode": {
+			server:      &serverInfo{Version: "6.8.0"},
+			minVersion:  multiTenantMinVersion,
+			multiTenant: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := provider{data: data, server: test.server}
+			diags := p.requireServer("feature", test.minVersion, test.multiTenant)
+			if test.wantErr == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected error: %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() || !strings.Contains(diags[0].Detail(), test.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", test.wantErr, diags)
+			}
+		})
+	}
+}
+
+func TestServerAtLeast(t *testing.T) {
+	if !(provider{}).serverAtLeast(instanceOverwriteMinVersion) {
+		t.Error("a server that was not detected should be assumed to be recent enough")
+	}
+	old := provider{server: &serverInfo{Version: "6.0.0"}}
+	if old.serverAtLeast(instanceOverwriteMinVersion) {
+		t.Errorf("6.0.0 should not be at least %s", instanceOverwriteMinVersion)
+	}
+	recent := provider{server: &serverInfo{Version: "6.9.0"}}
+	if !recent.serverAtLeast(instanceOverwriteMinVersion) {
+		t.Errorf("6.9.0 should be at least %s", instanceOverwriteMinVersion)
+	}
+}
+
+func TestMapperMinVersion(t *testing.T) {
+	if mapperMinVersion("outgoing") != outgoingMapperMinVersion {
+		t.Error("outgoing mappers should require outgoingMapperMinVersion")
+	}
+	if mapperMinVersion("incoming") != classifierMinVersion {
+		t.Error("incoming mappers should require classifierMinVersion")
+	}
+}
@@ -0,0 +1,669 @@
+package xsoar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sshOutputTailLines is the number of output lines of a failed command included in its error
+const sshOutputTailLines = 30
+
+// parseSSHSigner parses a private key, decrypting it with the passphrase if one is given, and pairs it with a
+// certificate if one is given
+func parseSSHSigner(key, passphrase, certificate string) (ssh.Signer, error) {
+	var signer ssh.Signer
+	var err error
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(key))
+	}
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("private key is encrypted and no passphrase was given")
+		}
+		return nil, fmt.Errorf("could not parse private key: %s", err)
+	}
+	if certificate == "" {
+		return signer, nil
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(certificate))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse certificate: %s", err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("certificate is a plain public key, not an SSH certificate")
+	}
+	signer, err = ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		return nil, fmt.Errorf("certificate does not match private key: %s", err)
+	}
+	return signer, nil
+}
+
+// sshAuth holds the credentials for a single SSH connection
+type sshAuth struct {
+	Key         string
+	Passphrase  string
+	Certificate string
+	Password    string
+	UseAgent    bool
+}
+
+// methods builds the authentication methods in the order they are offered to the server: key, agent, password.
+// The returned closer releases the agent connection, if one was opened.
+func (a sshAuth) methods() ([]ssh.AuthMethod, io.Closer, error) {
+	var methods []ssh.AuthMethod
+	var closer io.Closer
+	if a.Key != "" {
+		signer, err := parseSSHSigner(a.Key, a.Passphrase, a.Certificate)
+		if err != nil {
+			return nil, nil, err
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	if a.UseAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, nil, fmt.Errorf("ssh agent requested but SSH_AUTH_SOCK is not set")
+		}
+		agentConn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not connect to ssh agent: %s", err)
+		}
+		methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+		closer = agentConn
+	}
+	if a.Password != "" {
+		methods = append(methods, ssh.Password(a.Password))
+	}
+	if len(methods) == 0 {
+		return nil, nil, fmt.Errorf("no ssh authentication method configured")
+	}
+	return methods, closer, nil
+}
+
+// hostKeyVerifier checks the key presented by an SSH server. A pinned key or fingerprint takes precedence, then a
+// known_hosts file, then the key recorded in state on first use. When none of these are available the presented key
+// is trusted and recorded (trust on first use).
+type hostKeyVerifier struct {
+	pinnedKey         ssh.PublicKey
+	pinnedFingerprint string
+	knownHosts        ssh.HostKeyCallback
+	recordedKey       ssh.PublicKey
+
+	// Observed is the presented key in authorized_keys format
+	Observed string
+	// TrustedOnFirstUse is set when the key was accepted because none was configured or recorded
+	TrustedOnFirstUse bool
+	// Err is the verification failure, if any. ssh.Dial flattens callback errors into strings so it is kept here.
+	Err error
+}
+
+func newHostKeyVerifier(hostKey, knownHostsFile, observedHostKey string) (*hostKeyVerifier, error) {
+	v := &hostKeyVerifier{}
+	hostKey = strings.TrimSpace(hostKey)
+	if hostKey != "" {
+		if strings.HasPrefix(hostKey, "SHA256:") {
+			v.pinnedFingerprint = hostKey
+		} else {
+			key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+			if err != nil {
+				return nil, fmt.Errorf("host_key must be a public key in authorized_keys format or a SHA256 fingerprint: %s", err)
+			}
+			v.pinnedKey = key
+		}
+	}
+	if knownHostsFile != "" {
+		callback, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load known_hosts_file %s: %s", knownHostsFile, err)
+		}
+		v.knownHosts = callback
+	}
+	if observedHostKey != "" {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(observedHostKey))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse recorded host key: %s", err)
+		}
+		v.recordedKey = key
+	}
+	return v, nil
+}
+
+// Callback verifies the presented key and records it
+func (v *hostKeyVerifier) Callback(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	v.Observed = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	actual := ssh.FingerprintSHA256(key)
+	switch {
+	case v.pinnedFingerprint != "":
+		if actual != v.pinnedFingerprint {
+			v.Err = fmt.Errorf("host key mismatch for %s: expected %s, got %s", hostname, v.pinnedFingerprint, actual)
+		}
+	case v.pinnedKey != nil:
+		if !bytes.Equal(key.Marshal(), v.pinnedKey.Marshal()) {
+			v.Err = fmt.Errorf("host key mismatch for %s: expected %s, got %s", hostname, ssh.FingerprintSHA256(v.pinnedKey), actual)
+		}
+	case v.knownHosts != nil:
+		if err := v.knownHosts(hostname, remote, key); err != nil {
+			var keyErr *knownhosts.KeyError
+			if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+				v.Err = fmt.Errorf("host %s (key %s) not found in known_hosts_file", hostname, actual)
+			} else {
+				v.Err = fmt.Errorf("host key verification against known_hosts_file failed for %s (key %s): %s", hostname, actual, err)
+			}
+		}
+	case v.recordedKey != nil:
+		if !bytes.Equal(key.Marshal(), v.recordedKey.Marshal()) {
+			v.Err = fmt.Errorf("host key for %s changed since it was first recorded: expected %s, got %s", hostname, ssh.FingerprintSHA256(v.recordedKey), actual)
+		}
+	default:
+		v.TrustedOnFirstUse = true
+	}
+	return v.Err
+}
+
+// hostKeyError is returned when a server in the connection chain presents an unexpected host key
+type hostKeyError struct {
+	err error
+}
+
+func (e *hostKeyError) Error() string {
+	return e.err.Error()
+}
+
+// sshHop is a single connection in an SSH chain. All hops but the last are jump hosts.
+type sshHop struct {
+	Address  string
+	Config   *ssh.ClientConfig
+	Verifier *hostKeyVerifier
+	// Closer releases resources held by the authentication methods, may be nil
+	Closer io.Closer
+}
+
+// sshConnection is a connection to the last hop of a chain, closing it closes every hop
+type sshConnection struct {
+	*ssh.Client
+	jumps []*ssh.Client
+	hops  []sshHop
+}
+
+func (c *sshConnection) Close() error {
+	err := c.Client.Close()
+	for i := len(c.jumps) - 1; i >= 0; i-- {
+		c.jumps[i].Close()
+	}
+	closeHops(c.hops)
+	return err
+}
+
+func closeHops(hops []sshHop) {
+	for _, hop := range hops {
+		if hop.Closer != nil {
+			hop.Closer.Close()
+		}
+	}
+}
+
+// sshTarget holds the settings needed to connect to a machine managed over SSH, such as a host or an engine
+type sshTarget struct {
+	Address        string
+	User           string
+	Key            string
+	Passphrase     string
+	Certificate    string
+	Password       string
+	UseAgent       bool
+	HostKey        string
+	KnownHostsFile string
+	Bastions       []Bastion
+}
+
+// hops builds the SSH connection chain to the target through its bastions. The returned verifier belongs to the
+// target itself and records the key it presented.
+func (t sshTarget) hops(observedHostKey string) ([]sshHop, *hostKeyVerifier, error) {
+	var hops []sshHop
+	for _, bastion := range t.Bastions {
+		verifier, err := newHostKeyVerifier(bastion.HostKey.Value, t.KnownHostsFile, bastion.ObservedHostKey.Value)
+		if err != nil {
+			closeHops(hops)
+			return nil, nil, fmt.Errorf("bastion %s: %s", bastion.Host.Value, err)
+		}
+		auth, closer, err := sshAuth{
+			Key:         bastion.SSHKey.Value,
+			Passphrase:  bastion.SSHKeyPassphrase.Value,
+			Certificate: bastion.SSHCertificate.Value,
+			Password:    bastion.SSHPassword.Value,
+			UseAgent:    bastion.SSHUseAgent.Value,
+		}.methods()
+		if err != nil {
+			closeHops(hops)
+			return nil, nil, fmt.Errorf("bastion %s: %s", bastion.Host.Value, err)
+		}
+		hops = append(hops, sshHop{
+			Address:  bastion.Host.Value,
+			Config:   &ssh.ClientConfig{User: bastion.User.Value, Auth: auth},
+			Verifier: verifier,
+			Closer:   closer,
+		})
+	}
+
+	verifier, err := newHostKeyVerifier(t.HostKey, t.KnownHostsFile, observedHostKey)
+	if err != nil {
+		closeHops(hops)
+		return nil, nil, err
+	}
+	auth, closer, err := sshAuth{
+		Key:         t.Key,
+		Passphrase:  t.Passphrase,
+		Certificate: t.Certificate,
+		Password:    t.Password,
+		UseAgent:    t.UseAgent,
+	}.methods()
+	if err != nil {
+		closeHops(hops)
+		return nil, nil, err
+	}
+	hops = append(hops, sshHop{
+		Address:  t.Address,
+		Config:   &ssh.ClientConfig{User: t.User, Auth: auth},
+		Verifier: verifier,
+		Closer:   closer,
+	})
+	return hops, verifier, nil
+}
+
+// validateSSHConfig checks the SSH credentials of a resource, or of the block at parent, can be used, so mistakes
+// are reported during plan
+func validateSSHConfig(parent tfpath.Path, key types.String, passphrase types.String, certificate types.String, password types.String, useAgent types.Bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if key.Null && password.Null && !useAgent.Value && !useAgent.Unknown {
+		if len(parent.Steps()) == 0 {
+			diags.AddError(
+				"Missing SSH authentication",
+				"At least one of ssh_key, ssh_password or ssh_use_agent must be set",
+			)
+		} else {
+			diags.AddAttributeError(
+				parent,
+				"Missing SSH authentication",
+				"At least one of ssh_key, ssh_password or ssh_use_agent must be set",
+			)
+		}
+	}
+	if key.Null || key.Unknown || passphrase.Unknown || certificate.Unknown {
+		if !certificate.Null && key.Null {
+			diags.AddAttributeError(
+				parent.AtName("ssh_certificate"),
+				"Invalid SSH certificate",
+				"ssh_certificate requires ssh_key to be set",
+			)
+		}
+		return diags
+	}
+	_, err := parseSSHSigner(key.Value, passphrase.Value, certificate.Value)
+	if err != nil {
+		diags.AddAttributeError(
+			parent.AtName("ssh_key"),
+			"Invalid SSH key",
+			"Could not use ssh_key: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// validateBastions checks the SSH credentials of every bastion can be used
+func validateBastions(bastions []Bastion) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for i, b := range bastions {
+		diags.Append(validateSSHConfig(tfpath.Root("bastion").AtListIndex(i), b.SSHKey, b.SSHKeyPassphrase, b.SSHCertificate, b.SSHPassword, b.SSHUseAgent)...)
+	}
+	return diags
+}
+
+// bastionBlock is the schema of the bastion blocks of the resources managed over SSH
+func bastionBlock() tfsdk.Block {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Block{
+		NestingMode: tfsdk.BlockNestingModeList,
+		Attributes: map[string]tfsdk.Attribute{
+			"host": {
+				Type:     types.StringType,
+				Required: true,
+			},
+			"user": {
+				Type:     types.StringType,
+				Required: true,
+			},
+			"ssh_key": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_key_passphrase": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_certificate": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"ssh_password": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_use_agent": {
+				Type:     types.BoolType,
+				Optional: true,
+			},
+			"host_key": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"observed_host_key": {
+				Type:          types.StringType,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, bastionHostKeyModifier{}),
+			},
+		},
+	}
+}
+
+// bastionHostKeyModifier keeps the host key recorded for a bastion as long as the bastion at that position is the
+// same host. A bastion that is added or replaced gets its key recorded again on first use.
+type bastionHostKeyModifier struct{}
+
+func (m bastionHostKeyModifier) Description(_ context.Context) string {
+	return "Once recorded, the host key does not change unless the bastion host changes."
+}
+
+func (m bastionHostKeyModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m bastionHostKeyModifier) Modify(ctx context.Context, req tfsdk.ModifyAttributePlanRequest, resp *tfsdk.ModifyAttributePlanResponse) {
+	if req.AttributeState == nil || resp.AttributePlan == nil || req.AttributeState.IsNull() || !resp.AttributePlan.IsUnknown() {
+		return
+	}
+	hostPath := req.AttributePath.ParentPath().AtName("host")
+	var stateHost, planHost types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, hostPath, &stateHost)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, hostPath, &planHost)...)
+	if resp.Diagnostics.HasError() || planHost.Unknown || !planHost.Equal(stateHost) {
+		return
+	}
+	resp.AttributePlan = req.AttributeState
+}
+
+// observedBastions returns a copy of the bastions of the connection with the host key each one presented
+func (c *sshConnection) observedBastions(bastions []Bastion) []Bastion {
+	result := make([]Bastion, len(bastions))
+	for i, bastion := range bastions {
+		bastion.ObservedHostKey = types.String{Value: c.hops[i].Verifier.Observed}
+		result[i] = bastion
+	}
+	return result
+}
+
+// recordHostKeys returns the host key of the target and its bastions with their host keys recorded. A key is missing
+// when the resource was imported, or when a bastion was added since the last connection, so the target is connected to
+// and every key is trusted on first use, as on creation. observedHostKey is returned as is when nothing is missing.
+func recordHostKeys(ctx context.Context, target sshTarget, observedHostKey string) (string, []Bastion, diag.Diagnostics) {
+	recorded := observedHostKey != "" || target.Address == ""
+	for _, bastion := range target.Bastions {
+		if bastion.ObservedHostKey.Unknown || bastion.ObservedHostKey.Null {
+			recorded = false
+		}
+	}
+	if recorded {
+		return observedHostKey, target.Bastions, nil
+	}
+	conn, verifier, diags := connectSSH(ctx, target, observedHostKey)
+	if diags.HasError() {
+		return "", nil, diags
+	}
+	defer conn.Close()
+	return verifier.Observed, conn.observedBastions(target.Bastions), diags
+}
+
+// connectSSH opens an SSH connection to the target, through any bastions
+func connectSSH(ctx context.Context, target sshTarget, observedHostKey string) (*sshConnection, *hostKeyVerifier, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	hops, verifier, err := target.hops(observedHostKey)
+	if err != nil {
+		diags.AddError(
+			"Invalid SSH configuration",
+			err.Error(),
+		)
+		return nil, nil, diags
+	}
+	conn, err := dialSSH(ctx, hops, 300*time.Second)
+	if err != nil {
+		var keyErr *hostKeyError
+		if errors.As(err, &keyErr) {
+			diags.AddError(
+				"Host key verification failed",
+				"Refusing to connect to "+target.Address+": "+keyErr.Error(),
+			)
+			return nil, nil, diags
+		}
+		diags.AddError(
+			"Error connecting to host",
+			"Could not connect to "+target.Address+": "+err.Error(),
+		)
+		return nil, nil, diags
+	}
+	return conn, verifier, diags
+}
+
+// dialSSH connects through each hop in turn, retrying until the timeout unless a host key is rejected
+func dialSSH(ctx context.Context, hops []sshHop, timeout time.Duration) (*sshConnection, error) {
+	var conn *sshConnection
+	err := resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		var jumps []*ssh.Client
+		var client *ssh.Client
+		for i, hop := range hops {
+			hop.Config.HostKeyCallback = hop.Verifier.Callback
+			var conErr error
+			if i == 0 {
+				client, conErr = ssh.Dial("tcp", hop.Address, hop.Config)
+			} else {
+				client, conErr = dialThrough(client, hop)
+			}
+			if conErr != nil {
+				for j := len(jumps) - 1; j >= 0; j-- {
+					jumps[j].Close()
+				}
+				if hop.Verifier.Err != nil {
+					return resource.NonRetryableError(&hostKeyError{err: hop.Verifier.Err})
+				}
+				return resource.RetryableError(fmt.Errorf("error connecting to %s over ssh: %s", hop.Address, conErr))
+			}
+			if hop.Verifier.TrustedOnFirstUse {
+				tflog.SubsystemInfo(withLogSubsystem(ctx, sshLogSubsystem), sshLogSubsystem, "no host key configured, trusting the presented key on first use", map[string]interface{}{
+					"address": hop.Address,
+					"key":     hop.Verifier.Observed,
+				})
+			}
+			if i < len(hops)-1 {
+				jumps = append(jumps, client)
+			}
+		}
+		conn = &sshConnection{Client: client, jumps: jumps, hops: hops}
+		return nil
+	})
+	if err != nil {
+		closeHops(hops)
+	}
+	return conn, err
+}
+
+// dialThrough opens a connection to hop tunnelled over an existing client
+func dialThrough(jump *ssh.Client, hop sshHop) (*ssh.Client, error) {
+	tunnel, err := jump.Dial("tcp", hop.Address)
+	if err != nil {
+		return nil, err
+	}
+	c, chans, reqs, err := ssh.NewClientConn(tunnel, hop.Address, hop.Config)
+	if err != nil {
+		tunnel.Close()
+		return nil, err
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// sshCommandError is returned when a remote command fails, it carries the end of the command's output
+type sshCommandError struct {
+	Step       string
+	ExitStatus int
+	Tail       []string
+	err        error
+}
+
+func (e *sshCommandError) Error() string {
+	msg := fmt.Sprintf("%s failed: %s", e.Step, e.err)
+	if e.ExitStatus >= 0 {
+		msg = fmt.Sprintf("%s failed with exit code %d", e.Step, e.ExitStatus)
+	}
+	if len(e.Tail) > 0 {
+		msg += fmt.Sprintf("\n\nLast %d lines of output:\n%s", len(e.Tail), strings.Join(e.Tail, "\n"))
+	}
+	return msg
+}
+
+// outputLogger writes each line of a command's output to the Terraform log and keeps the most recent lines
+type outputLogger struct {
+	ctx     context.Context
+	step    string
+	stream  string
+	mu      *sync.Mutex
+	tail    *[]string
+	partial []byte
+}
+
+func (o *outputLogger) Write(p []byte) (int, error) {
+	o.partial = append(o.partial, p...)
+	for {
+		i := bytes.IndexByte(o.partial, '\n')
+		if i < 0 {
+			break
+		}
+		o.line(string(bytes.TrimRight(o.partial[:i], "\r")))
+		o.partial = o.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+func (o *outputLogger) Flush() {
+	if len(o.partial) > 0 {
+		o.line(string(o.partial))
+		o.partial = nil
+	}
+}
+
+func (o *outputLogger) line(line string) {
+	tflog.SubsystemInfo(o.ctx, sshLogSubsystem, line, map[string]interface{}{"step": o.step, "stream": o.stream})
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	*o.tail = append(*o.tail, line)
+	if len(*o.tail) > sshOutputTailLines {
+		*o.tail = (*o.tail)[len(*o.tail)-sshOutputTailLines:]
+	}
+}
+
+// runSSHCommand runs cmd in a new session, streaming stdout and stderr to the Terraform log. The command itself is not
+// logged as it may contain credentials, step names it instead.
+func runSSHCommand(ctx context.Context, conn *sshConnection, step string, cmd string) error {
+	session, err := conn.NewSession()
+	if err != nil {
+		return fmt.Errorf("could not create ssh session: %s", err)
+	}
+	defer session.Close()
+
+	ctx = withLogSubsystem(ctx, sshLogSubsystem)
+	var mu sync.Mutex
+	var tail []string
+	stdout := &outputLogger{ctx: ctx, step: step, stream: "stdout", mu: &mu, tail: &tail}
+	stderr := &outputLogger{ctx: ctx, step: step, stream: "stderr", mu: &mu, tail: &tail}
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	tflog.SubsystemInfo(ctx, sshLogSubsystem, "running "+step)
+	err = session.Run(cmd)
+	stdout.Flush()
+	stderr.Flush()
+	if err != nil {
+		cmdErr := &sshCommandError{Step: step, ExitStatus: -1, Tail: tail, err: err}
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) {
+			cmdErr.ExitStatus = exitErr.ExitStatus()
+		}
+		return cmdErr
+	}
+	return nil
+}
+
+// uploadSSHFile copies size bytes from r to remotePath using the scp sink protocol
+func uploadSSHFile(ctx context.Context, conn *sshConnection, r io.Reader, size int64, remotePath string, mode os.FileMode) error {
+	session, err := conn.NewSession()
+	if err != nil {
+		return fmt.Errorf("could not create ssh session: %s", err)
+	}
+	defer session.Close()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("could not open scp input: %s", err)
+	}
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+
+	dir, name := path.Split(remotePath)
+	if dir == "" {
+		dir = "."
+	}
+	ctx = withLogSubsystem(ctx, sshLogSubsystem)
+	tflog.SubsystemInfo(ctx, sshLogSubsystem, "uploading "+remotePath, map[string]interface{}{"bytes": size})
+	err = session.Start("scp -qt " + dir)
+	if err != nil {
+		return fmt.Errorf("could not start scp: %s", err)
+	}
+	_, err = fmt.Fprintf(stdin, "C%04o %d %s\n", mode.Perm(), size, name)
+	if err == nil {
+		_, err = io.CopyN(stdin, r, size)
+	}
+	if err == nil {
+		_, err = stdin.Write([]byte{0})
+	}
+	stdin.Close()
+	waitErr := session.Wait()
+	if err != nil {
+		return fmt.Errorf("could not upload %s: %s", remotePath, err)
+	}
+	if waitErr != nil {
+		return fmt.Errorf("could not upload %s: %s %s", remotePath, waitErr, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
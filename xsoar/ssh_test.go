@@ -0,0 +1,154 @@
+package xsoar
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSSHTargetHopsBastions(t *testing.T) {
+	recorded := testHostKey(t)
+	presented := testHostKey(t)
+	target := sshTarget{
+		Address:  "host.example.com:22",
+		User:     "admin",
+		Password: "secret",
+		Bastions: []Bastion{{
+			Host:            types.String{Value: "bastion.example.com:22"},
+			User:            types.String{Value: "jump"},
+			SSHKey:          types.String{Null: true},
+			SSHPassword:     types.String{Value: "bastion-secret"},
+			ObservedHostKey: types.String{Value: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(recorded)))},
+		}},
+	}
+	hops, verifier, err := target.hops("")
+	if err != nil {
+		t.Fatalf("a bastion with only a password should be usable: %s", err)
+	}
+	defer closeHops(hops)
+	if len(hops) != 2 || hops[1].Verifier != verifier {
+		t.Fatalf("expected the bastion and the target as hops, got %d", len(hops))
+	}
+	if hops[0].Config.User != "jump" || len(hops[0].Config.Auth) != 1 {
+		t.Fatalf("unexpected bastion hop %+v", hops[0].Config)
+	}
+
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 22}
+	if err := hops[0].Verifier.Callback("bastion.example.com:22", addr, recorded); err != nil {
+		t.Fatalf("the recorded bastion key should be accepted: %s", err)
+	}
+	if err := hops[0].Verifier.Callback("bastion.example.com:22", addr, presented); err == nil {
+		t.Fatal("a bastion key different from the recorded one should be rejected")
+	}
+
+	target.Bastions[0].ObservedHostKey = types.String{Unknown: true}
+	hops, _, err = target.hops("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeHops(hops)
+	if err := hops[0].Verifier.Callback("bastion.example.com:22", addr, presented); err != nil {
+		t.Fatalf("a bastion without a recorded key should be trusted on first use: %s", err)
+	}
+}
+
+func TestValidateBastions(t *testing.T) {
+	diags := validateBastions([]Bastion{
+		{SSHPassword: types.String{Value: "secret"}, SSHKey: types.String{Null: true}, SSHCertificate: types.String{Null: true}},
+		{SSHPassword: types.String{Null: true}, SSHKey: types.String{Null: true}, SSHCertificate: types.String{Null: true}},
+	})
+	if len(diags) != 1 || diags[0].Summary() != "Missing SSH authentication" {
+		t.Fatalf("expected only the second bastion to be missing authentication, got %v", diags)
+	}
+}
+
+func TestRecordHostKeys(t *testing.T) {
+	address, hostKey, connections := testSSHServer(t)
+	presented := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostKey)))
+	target := sshTarget{Address: address, User: "admin", Password: "secret"}
+	ctx := context.Background()
+
+	// an imported resource has no recorded key yet
+	observed, _, diags := recordHostKeys(ctx, target, "")
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if observed != presented {
+		t.Fatalf("recorded %q, want the presented key %q", observed, presented)
+	}
+	if atomic.LoadInt32(connections) != 1 {
+		t.Fatalf("connected %d times, want 1", atomic.LoadInt32(connections))
+	}
+
+	// nothing to record
+	observed, _, diags = recordHostKeys(ctx, target, presented)
+	if diags.HasError() || observed != presented || atomic.LoadInt32(connections) != 1 {
+		t.Fatalf("expected the recorded key without connecting, got %q, %d connections, %v", observed, atomic.LoadInt32(connections), diags)
+	}
+	observed, _, diags = recordHostKeys(ctx, sshTarget{}, "")
+	if diags.HasError() || observed != "" || atomic.LoadInt32(connections) != 1 {
+		t.Fatalf("expected no connection without an address, got %q, %d connections, %v", observed, atomic.LoadInt32(connections), diags)
+	}
+}
+
+// testSSHServer starts an SSH server accepting any password, and returns its address, its host key and a counter of
+// the connections it accepted
+func testSSHServer(t *testing.T) (string, ssh.PublicKey, *int32) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) { return nil, nil },
+	}
+	config.AddHostKey(signer)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	var connections int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&connections, 1)
+			go func() {
+				defer conn.Close()
+				_, channels, requests, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(requests)
+				for channel := range channels {
+					_ = channel.Reject(ssh.Prohibited, "no sessions")
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), signer.PublicKey(), &connections
+}
+
+func testHostKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
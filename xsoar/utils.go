@@ -1,5 +1,7 @@
 package xsoar
 
+import "strings"
+
 func equalSliceString(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -11,3 +13,17 @@ func equalSliceString(a, b []string) bool {
 	}
 	return true
 }
+
+// shellQuote quotes s so that a POSIX shell passes it through as a single literal argument
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellJoin quotes each argument and joins them into a command line fragment
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,60 @@
+package xsoar
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want string
+	}{
+		"plain":          {"installer.sh", `'installer.sh'`},
+		"empty":          {"", `''`},
+		"space":          {"/tmp/my dir", `'/tmp/my dir'`},
+		"single quote":   {"it's", `'it'\''s'`},
+		"only quotes":    {"''", `''\'''\'''`},
+		"substitution":   {"$(rm -rf /)", `'$(rm -rf /)'`},
+		"variable":       {"$HOME", `'$HOME'`},
+		"backticks":      {"`id`", "'`id`'"},
+		"semicolon":      {"a; reboot", `'a; reboot'`},
+		"newline":        {"a\nreboot", "'a\nreboot'"},
+		"quote breakout": {"'; reboot; echo '", `''\''; reboot; echo '\'''`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := shellQuote(test.in)
+			if got != test.want {
+				t.Fatalf("shellQuote(%q) = %s, want %s", test.in, got, test.want)
+			}
+			// the shell must see the original string as a single word, without running anything in it
+			out, err := exec.Command("sh", "-c", "printf %s "+got).Output()
+			if err != nil {
+				t.Skipf("could not run sh: %s", err)
+			}
+			if string(out) != test.in {
+				t.Fatalf("sh read %q back as %q", test.in, string(out))
+			}
+		})
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	tests := map[string]struct {
+		in   []string
+		want string
+	}{
+		"none":      {nil, ""},
+		"flags":     {[]string{"-y", "-tools=false"}, `'-y' '-tools=false'`},
+		"injection": {[]string{"-external-address=a; reboot", "$(id)", "`id`"}, "'-external-address=a; reboot' '$(id)' '`id`'"},
+		"quote":     {[]string{"-temp-folder=/tmp/it's"}, `'-temp-folder=/tmp/it'\''s'`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := shellJoin(test.in); got != test.want {
+				t.Fatalf("shellJoin(%q) = %s, want %s", test.in, got, test.want)
+			}
+		})
+	}
+}
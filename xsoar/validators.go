@@ -0,0 +1,145 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// installerFlagAllowList holds the installer flags that may be passed through extra_flags
+var installerFlagAllowList = map[string]bool{
+	"do-not-start-server": true,
+	"docker":              true,
+	"elasticsearch-url":   true,
+	"external-address":    true,
+	"multi-tenant":        true,
+	"offline":             true,
+	"temp-folder":         true,
+	"tools":               true,
+}
+
+var installerFlagPattern = regexp.MustCompile(`^-([a-z][a-z0-9-]*)(=.*)?$`)
+
+// validateInstallerFlag checks a single extra flag has the form -name or -name=value and that name is allowed
+func validateInstallerFlag(flag string) error {
+	match := installerFlagPattern.FindStringSubmatch(flag)
+	if match == nil {
+		return fmt.Errorf("%q is not an installer flag, flags must have the form -name or -name=value", flag)
+	}
+	if !installerFlagAllowList[match[1]] {
+		allowed := make([]string, 0, len(installerFlagAllowList))
+		for name := range installerFlagAllowList {
+			allowed = append(allowed, "-"+name)
+		}
+		sort.Strings(allowed)
+		return fmt.Errorf("installer flag -%s is not allowed, allowed flags are: %s", match[1], strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// installerFlagsValidator checks every element of a list of installer flags against the allow-list
+type installerFlagsValidator struct{}
+
+func (v installerFlagsValidator) Description(_ context.Context) string {
+	return "each flag must be an allowed installer flag of the form -name or -name=value"
+}
+
+func (v installerFlagsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v installerFlagsValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var flags types.List
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &flags)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || flags.Null || flags.Unknown {
+		return
+	}
+	for i, elem := range flags.Elems {
+		flag, ok := elem.(types.String)
+		if !ok || flag.Null || flag.Unknown {
+			continue
+		}
+		if err := validateInstallerFlag(flag.Value); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				req.AttributePath.AtListIndex(i),
+				"Invalid installer flag",
+				err.Error(),
+			)
+		}
+	}
+}
+
+// stringOneOfValidator checks a string attribute is one of a fixed set of values
+type stringOneOfValidator struct {
+	values []string
+}
+
+func stringOneOf(values ...string) stringOneOfValidator {
+	return stringOneOfValidator{values: values}
+}
+
+func (v stringOneOfValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("value must be one of: %s", strings.Join(v.values, ", "))
+}
+
+func (v stringOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v stringOneOfValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var value types.String
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &value)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || value.Null || value.Unknown {
+		return
+	}
+	for _, allowed := range v.values {
+		if value.Value == allowed {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(
+		req.AttributePath,
+		"Invalid value",
+		fmt.Sprintf("%q is not valid, %s", value.Value, v.Description(ctx)),
+	)
+}
+
+// stringMatchesValidator checks a string attribute matches a regular expression
+type stringMatchesValidator struct {
+	pattern     *regexp.Regexp
+	description string
+}
+
+func stringMatches(pattern *regexp.Regexp, description string) stringMatchesValidator {
+	return stringMatchesValidator{pattern: pattern, description: description}
+}
+
+func (v stringMatchesValidator) Description(_ context.Context) string {
+	return "value must be " + v.description
+}
+
+func (v stringMatchesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v stringMatchesValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var value types.String
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &value)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || value.Null || value.Unknown {
+		return
+	}
+	if !v.pattern.MatchString(value.Value) {
+		resp.Diagnostics.AddAttributeError(
+			req.AttributePath,
+			"Invalid value",
+			fmt.Sprintf("%q is not valid, %s", value.Value, v.Description(ctx)),
+		)
+	}
+}
@@ -0,0 +1,44 @@
+package xsoar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateInstallerFlag(t *testing.T) {
+	tests := map[string]struct {
+		flag    string
+		wantErr string
+	}{
+		"flag":                  {flag: "-offline"},
+		"flag with value":       {flag: "-temp-folder=/data/tmp"},
+		"value with port":       {flag: "-external-address=xsoar.example.com:443"},
+		"value with quote":      {flag: "-temp-folder=/tmp/it's"},
+		"value with semicolon":  {flag: "-temp-folder=/tmp; reboot"},
+		"disallowed flag":       {flag: "-y", wantErr: "installer flag -y is not allowed"},
+		"disallowed with value": {flag: "-purge=true", wantErr: "installer flag -purge is not allowed"},
+		"double dash":           {flag: "--offline", wantErr: "is not an installer flag"},
+		"no dash":               {flag: "offline", wantErr: "is not an installer flag"},
+		"empty":                 {flag: "", wantErr: "is not an installer flag"},
+		"substitution":          {flag: "-$(reboot)", wantErr: "is not an installer flag"},
+		"backticks":             {flag: "-`reboot`", wantErr: "is not an installer flag"},
+		"semicolon":             {flag: "-offline;reboot", wantErr: "is not an installer flag"},
+		"newline":               {flag: "-offline\nreboot", wantErr: "is not an installer flag"},
+		"second flag":           {flag: "-offline -y", wantErr: "is not an installer flag"},
+		"upper case":            {flag: "-OFFLINE", wantErr: "is not an installer flag"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateInstallerFlag(test.flag)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}